@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+
+	"github.com/google/go-querystring/query"
 )
 
 // BillingState constants, for more info see:
@@ -14,6 +16,17 @@ const (
 	BillingStateDeleted   = "deleted"
 )
 
+// SyncState constants, for more info see:
+// https://docs.nylas.com/reference#account-sync-status
+const (
+	SyncStateRunning            = "running"
+	SyncStatePartial            = "partial"
+	SyncStateStopped            = "stopped"
+	SyncStateInvalid            = "invalid"
+	SyncStateInvalidCredentials = "invalid-credentials"
+	SyncStateSyncError          = "sync-error"
+)
+
 // OrganizationUnit constants specify either "label" or "folder", depending on the provider capabilities.
 const (
 	OrganizationUnitFolder = "folder"
@@ -53,6 +66,32 @@ type ManagementAccount struct {
 	Trial        bool   `json:"trial"`
 }
 
+// TokenState constants, for more info see:
+// https://docs.nylas.com/reference#token-info
+const (
+	TokenStateValid   = "valid"
+	TokenStateInvalid = "invalid"
+)
+
+// TokenInfo contains the details of an access token for an account.
+type TokenInfo struct {
+	// Comma separated list of the scopes granted to the token.
+	Scopes string `json:"scopes"`
+	// One of the TokenState* constants.
+	State string `json:"state"`
+	// Unix timestamps of when the token was created and last updated.
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// IPAddresses contains the IP addresses Nylas uses to connect to mail
+// servers, useful for allowlisting on IMAP and SMTP servers.
+type IPAddresses struct {
+	IPAddresses []string `json:"ip_addresses"`
+	// Unix timestamp of when the list was last updated.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
 // Account returns the account information for the user the client is
 // authenticated as.
 // See: https://docs.nylas.com/reference#account
@@ -66,19 +105,120 @@ func (c *Client) Account(ctx context.Context) (Account, error) {
 	return resp, c.do(req, &resp)
 }
 
-// Accounts returns the account information for all accounts.
+// AccountsOptions provides optional parameters to the Accounts method.
+type AccountsOptions struct {
+	View   string `url:"view,omitempty"`
+	Limit  int    `url:"limit,omitempty"`
+	Offset int    `url:"offset,omitempty"`
+	// Return accounts with the given billing state, one of the BillingState*
+	// constants.
+	BillingState string `url:"billing_state,omitempty"`
+	// Return accounts with the given sync state, one of the SyncState*
+	// constants.
+	SyncState string `url:"sync_state,omitempty"`
+	// Return accounts from the given provider, e.g gmail.
+	Provider string `url:"provider,omitempty"`
+	// Return accounts with the given email address.
+	Email string `url:"email,omitempty"`
+}
+
+// defaultAccountsPageSize is the number of accounts requested per page by
+// IterateAccounts when no limit is given.
+const defaultAccountsPageSize = 100
+
+// Accounts returns the account information for accounts which match the
+// filter specified by parameters.
 // See: https://docs.nylas.com/reference#aclient_idaccounts
-func (c *Client) Accounts(ctx context.Context) ([]ManagementAccount, error) {
+func (c *Client) Accounts(ctx context.Context, opts *AccountsOptions) ([]ManagementAccount, error) {
 	endpoint := fmt.Sprintf("/a/%s/accounts", c.clientID)
 	req, err := c.newAccountRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if opts != nil {
+		vs, err := query.Values(opts)
+		if err != nil {
+			return nil, err
+		}
+		appendQueryValues(req, vs)
+	}
+
 	var resp []ManagementAccount
 	return resp, c.do(req, &resp)
 }
 
+// AccountsCount returns the count of accounts which match the filter specified
+// by parameters.
+// See: https://docs.nylas.com/reference#aclient_idaccounts
+func (c *Client) AccountsCount(ctx context.Context, opts *AccountsOptions) (int, error) {
+	endpoint := fmt.Sprintf("/a/%s/accounts", c.clientID)
+	req, err := c.newAccountRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts == nil {
+		opts = &AccountsOptions{}
+	}
+	vs, err := query.Values(opts)
+	if err != nil {
+		return 0, err
+	}
+	vs.Set("view", ViewCount)
+	appendQueryValues(req, vs)
+
+	var resp countResponse
+	return resp.Count, c.do(req, &resp)
+}
+
+// IterateAccounts calls fn with every account which matches the filter
+// specified by parameters, requesting a page at a time.
+//
+// Pages are opts.Limit accounts long, or 100 if not set, starting from
+// opts.Offset. Iteration stops at the first non-nil error returned by fn,
+// which is then returned.
+func (c *Client) IterateAccounts(
+	ctx context.Context, opts *AccountsOptions, fn func(ManagementAccount) error,
+) error {
+	pageOpts := AccountsOptions{}
+	if opts != nil {
+		pageOpts = *opts
+	}
+	if pageOpts.Limit <= 0 {
+		pageOpts.Limit = defaultAccountsPageSize
+	}
+
+	for {
+		accounts, err := c.Accounts(ctx, &pageOpts)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			if err := fn(account); err != nil {
+				return err
+			}
+		}
+		if len(accounts) < pageOpts.Limit {
+			return nil
+		}
+		pageOpts.Offset += len(accounts)
+	}
+}
+
+// ManagementAccount returns the account information for an account by id.
+// See: https://docs.nylas.com/reference#get-an-account
+func (c *Client) ManagementAccount(ctx context.Context, id string) (ManagementAccount, error) {
+	endpoint := fmt.Sprintf("/a/%s/accounts/%s", c.clientID, id)
+	req, err := c.newAccountRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return ManagementAccount{}, err
+	}
+
+	var resp ManagementAccount
+	return resp, c.do(req, &resp)
+}
+
 // DeleteAccount deletes an account. Accounts deleted using this method are immediately unavailable.
 // See: https://developer.nylas.com/docs/api/#delete/a/client_id/accounts/id
 func (c *Client) DeleteAccount(ctx context.Context, id string) error {
@@ -128,3 +268,31 @@ func (c *Client) RevokeAccountTokens(ctx context.Context, id string, keepToken *
 	}
 	return c.do(req, nil)
 }
+
+// TokenInfo returns information about an access token of an account.
+// See: https://docs.nylas.com/reference#token-info
+func (c *Client) TokenInfo(ctx context.Context, id, accessToken string) (TokenInfo, error) {
+	endpoint := fmt.Sprintf("/a/%s/accounts/%s/token-info", c.clientID, id)
+	req, err := c.newAccountRequest(ctx, http.MethodPost, endpoint, map[string]interface{}{
+		"access_token": accessToken,
+	})
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	var resp TokenInfo
+	return resp, c.do(req, &resp)
+}
+
+// IPAddresses returns the IP addresses Nylas uses to connect to mail servers.
+// See: https://docs.nylas.com/reference#ip-addresses
+func (c *Client) IPAddresses(ctx context.Context) (IPAddresses, error) {
+	endpoint := fmt.Sprintf("/a/%s/ip_addresses", c.clientID)
+	req, err := c.newAccountRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return IPAddresses{}, err
+	}
+
+	var resp IPAddresses
+	return resp, c.do(req, &resp)
+}
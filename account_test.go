@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -51,13 +52,28 @@ func TestAccounts(t *testing.T) {
 		assertBasicAuth(t, r, clientSecret, "")
 		wantPath := fmt.Sprintf("/a/%s/accounts", clientID)
 		assertMethodPath(t, r, http.MethodGet, wantPath)
+		assertQueryParams(t, r, url.Values{
+			"billing_state": {"paid"},
+			"email":         {"example@example.com"},
+			"limit":         {"2"},
+			"offset":        {"4"},
+			"provider":      {"gmail"},
+			"sync_state":    {"running"},
+		})
 
 		_, _ = w.Write(managementAccountsJSON)
 	}))
 	defer ts.Close()
 
 	client := NewClient(clientID, clientSecret, withTestServer(ts))
-	got, err := client.Accounts(context.Background())
+	got, err := client.Accounts(context.Background(), &AccountsOptions{
+		Limit:        2,
+		Offset:       4,
+		BillingState: BillingStatePaid,
+		SyncState:    SyncStateRunning,
+		Provider:     "gmail",
+		Email:        "example@example.com",
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -174,6 +190,193 @@ func TestRevokeAccountTokens(t *testing.T) {
 	}
 }
 
+func TestAccountsCount(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		assertMethodPath(t, r, http.MethodGet, fmt.Sprintf("/a/%s/accounts", clientID))
+		assertQueryParams(t, r, url.Values{
+			"sync_state": {"stopped"},
+			"view":       {"count"},
+		})
+
+		_, _ = w.Write([]byte(`{"count": 12}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	got, err := client.AccountsCount(context.Background(), &AccountsOptions{
+		SyncState: SyncStateStopped,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 12 {
+		t.Errorf("AccountsCount: got %d; want 12", got)
+	}
+}
+
+func TestIterateAccounts(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	var offsets []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		assertMethodPath(t, r, http.MethodGet, fmt.Sprintf("/a/%s/accounts", clientID))
+
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+		switch offset {
+		case "":
+			_, _ = w.Write(managementAccountsJSON)
+		case "2":
+			_, _ = w.Write([]byte(`[{"id": "third"}]`))
+		default:
+			t.Errorf("unexpected offset: %q", offset)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	var got []string
+	err := client.IterateAccounts(context.Background(), &AccountsOptions{Limit: 2},
+		func(account ManagementAccount) error {
+			got = append(got, account.ID)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"622x1k5v1ujh55t6ucel7av4", "123rvgm1iccsgnjj7nn6jwu1", "third"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("IterateAccounts: (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(offsets, []string{"", "2"}); diff != "" {
+		t.Errorf("offsets: (-got +want):\n%s", diff)
+	}
+}
+
+func TestManagementAccount(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	accountID := "622x1k5v1ujh55t6ucel7av4"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		wantPath := fmt.Sprintf("/a/%s/accounts/%s", clientID, accountID)
+		assertMethodPath(t, r, http.MethodGet, wantPath)
+
+		_, _ = w.Write([]byte(`{
+			"account_id": "622x1k5v1ujh55t6ucel7av4",
+			"billing_state": "paid",
+			"email": "example@example.com",
+			"id": "622x1k5v1ujh55t6ucel7av4",
+			"provider": "yahoo",
+			"sync_state": "running",
+			"trial": true
+		}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	got, err := client.ManagementAccount(context.Background(), accountID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := ManagementAccount{
+		ID:           "622x1k5v1ujh55t6ucel7av4",
+		AccountID:    "622x1k5v1ujh55t6ucel7av4",
+		BillingState: "paid",
+		Email:        "example@example.com",
+		Provider:     "yahoo",
+		SyncState:    "running",
+		Trial:        true,
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ManagementAccount: (-got +want):\n%s", diff)
+	}
+}
+
+func TestTokenInfo(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	accountID := "accountID"
+	wantBody := []byte(`{"access_token":"token"}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		wantPath := fmt.Sprintf("/a/%s/accounts/%s/token-info", clientID, accountID)
+		assertMethodPath(t, r, http.MethodPost, wantPath)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"created_at": 1563496685,
+			"scopes": "calendar,email,contacts",
+			"state": "valid",
+			"updated_at": 1563496690
+		}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	got, err := client.TokenInfo(context.Background(), accountID, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := TokenInfo{
+		Scopes:    "calendar,email,contacts",
+		State:     TokenStateValid,
+		CreatedAt: 1563496685,
+		UpdatedAt: 1563496690,
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("TokenInfo: (-got +want):\n%s", diff)
+	}
+}
+
+func TestIPAddresses(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		wantPath := fmt.Sprintf("/a/%s/ip_addresses", clientID)
+		assertMethodPath(t, r, http.MethodGet, wantPath)
+
+		_, _ = w.Write([]byte(`{
+			"ip_addresses": ["39.45.235.23", "23.10.341.123"],
+			"updated_at": 1544658529
+		}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	got, err := client.IPAddresses(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := IPAddresses{
+		IPAddresses: []string{"39.45.235.23", "23.10.341.123"},
+		UpdatedAt:   1544658529,
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("IPAddresses: (-got +want):\n%s", diff)
+	}
+}
+
 var accountJSON = []byte(`{
     "id": "awa6ltos76vz5hvphkp8k17nt",
     "account_id": "awa6ltos76vz5hvphkp8k17nt",
@@ -0,0 +1,167 @@
+package nylas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AccountHealth is the last known state of an account in an AccountPool.
+type AccountHealth struct {
+	// SyncState and BillingState as last returned by the Accounts method.
+	SyncState    string
+	BillingState string
+	// TokenRevoked is true when a request made with the account's access
+	// token failed with an authentication error and the token was evicted.
+	TokenRevoked bool
+	// LastError is the most recent API error returned for the account.
+	LastError error
+	UpdatedAt time.Time
+}
+
+// Healthy reports whether the account has a usable access token and is
+// neither stopped nor cancelled.
+func (h AccountHealth) Healthy() bool {
+	if h.TokenRevoked {
+		return false
+	}
+	switch h.SyncState {
+	case "", SyncStateRunning, SyncStatePartial:
+	default:
+		return false
+	}
+	switch h.BillingState {
+	case BillingStateCancelled, BillingStateDeleted:
+		return false
+	}
+	return true
+}
+
+// AccountPool provides Clients for many accounts keyed by their Nylas account
+// ID, using the access tokens held in a WritableTokenStore.
+type AccountPool struct {
+	client *Client
+	tokens WritableTokenStore
+
+	mu     sync.RWMutex
+	health map[string]AccountHealth
+}
+
+// NewAccountPool returns a new AccountPool, the client must have the client
+// ID and secret set for Refresh to work.
+func NewAccountPool(client *Client, tokens WritableTokenStore) *AccountPool {
+	return &AccountPool{
+		client: client,
+		tokens: tokens,
+		health: make(map[string]AccountHealth),
+	}
+}
+
+// Client returns a Client authenticated as the account with the given ID.
+//
+// API errors returned by the client are recorded against the account's
+// health, and an authentication error evicts the access token from the
+// store so later calls return ErrTokenNotFound until a new token is
+// added.
+func (p *AccountPool) Client(ctx context.Context, accountID string) (*Client, error) {
+	token, err := p.tokens.AccessToken(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", accountID, err)
+	}
+
+	c := p.client.As(token)
+	next := c.errorHandler
+	c.errorHandler = func(e error) error {
+		p.recordError(accountID, token, e)
+		if next != nil {
+			return next(e)
+		}
+		return e
+	}
+	return c, nil
+}
+
+// AddAccount stores the access token for the account with the given ID,
+// clearing any previous revocation.
+func (p *AccountPool) AddAccount(ctx context.Context, accountID, token string) error {
+	if err := p.tokens.SetAccessToken(ctx, accountID, token); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.health[accountID]
+	h.TokenRevoked = false
+	h.LastError = nil
+	h.UpdatedAt = time.Now()
+	p.health[accountID] = h
+	return nil
+}
+
+// RemoveAccount deletes the access token and health of the account with the
+// given ID.
+func (p *AccountPool) RemoveAccount(ctx context.Context, accountID string) error {
+	if err := p.tokens.DeleteAccessToken(ctx, accountID); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.health, accountID)
+	return nil
+}
+
+// Refresh updates the sync and billing state of every account from the
+// Accounts method, paging through all accounts.
+func (p *AccountPool) Refresh(ctx context.Context) error {
+	now := time.Now()
+	return p.client.IterateAccounts(ctx, nil, func(account ManagementAccount) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		h := p.health[account.ID]
+		h.SyncState = account.SyncState
+		h.BillingState = account.BillingState
+		h.UpdatedAt = now
+		p.health[account.ID] = h
+		return nil
+	})
+}
+
+// Health returns the last known health of the account with the given ID,
+// the boolean is false if nothing is known about the account.
+func (p *AccountPool) Health(accountID string) (AccountHealth, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	h, ok := p.health[accountID]
+	return h, ok
+}
+
+// Healths returns the last known health of every account keyed by ID.
+func (p *AccountPool) Healths() map[string]AccountHealth {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	healths := make(map[string]AccountHealth, len(p.health))
+	for id, h := range p.health {
+		healths[id] = h
+	}
+	return healths
+}
+
+func (p *AccountPool) recordError(accountID, token string, e error) {
+	revoked := hasStatusCode(e, http.StatusUnauthorized)
+	if revoked {
+		// Only evict the token the failing client was using, it may have
+		// been replaced in the meantime.
+		_ = p.tokens.DeleteAccessTokenIf(context.Background(), accountID, token)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.health[accountID]
+	h.LastError = e
+	h.TokenRevoked = h.TokenRevoked || revoked
+	h.UpdatedAt = time.Now()
+	p.health[accountID] = h
+}
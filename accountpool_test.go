@@ -0,0 +1,98 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestAccountPoolClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		if user == "revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "Could not verify access credential.", "type": "invalid_request_error"}`))
+			return
+		}
+		_, _ = w.Write(accountJSON)
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	tokens := testTokenStore(t, map[string]string{"good": "valid", "bad": "revoked"})
+	pool := NewAccountPool(NewClient("", "", withTestServer(ts)), tokens)
+
+	client, err := pool.Client(ctx, "good")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Account(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client, err = pool.Client(ctx, "bad")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Account(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := tokens.AccessToken(ctx, "bad"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("revoked token: got error %v; want %v", err, ErrTokenNotFound)
+	}
+	if _, err := pool.Client(ctx, "bad"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("revoked client: got error %v; want %v", err, ErrTokenNotFound)
+	}
+
+	h, ok := pool.Health("bad")
+	if !ok || !h.TokenRevoked || h.LastError == nil || h.Healthy() {
+		t.Errorf("revoked health: got %+v, %v", h, ok)
+	}
+	if _, ok := pool.Health("good"); ok {
+		t.Error("expected no health recorded for good account")
+	}
+
+	if err := pool.AddAccount(ctx, "bad", "valid"); err != nil {
+		t.Fatalf("add account: %v", err)
+	}
+	if h, _ := pool.Health("bad"); !h.Healthy() {
+		t.Errorf("re-added health: got %+v; want healthy", h)
+	}
+}
+
+func TestAccountPoolRefresh(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		assertMethodPath(t, r, http.MethodGet, fmt.Sprintf("/a/%s/accounts", clientID))
+		_, _ = w.Write([]byte(`[
+			{"id": "a", "billing_state": "paid", "sync_state": "running"},
+			{"id": "b", "billing_state": "cancelled", "sync_state": "stopped"}
+		]`))
+	}))
+	defer ts.Close()
+
+	pool := NewAccountPool(NewClient(clientID, clientSecret, withTestServer(ts)), NewMemoryTokenStore())
+	if err := pool.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]AccountHealth{
+		"a": {SyncState: SyncStateRunning, BillingState: BillingStatePaid},
+		"b": {SyncState: SyncStateStopped, BillingState: BillingStateCancelled},
+	}
+	got := pool.Healths()
+	if diff := cmp.Diff(got, want, cmpopts.IgnoreFields(AccountHealth{}, "UpdatedAt")); diff != "" {
+		t.Errorf("Healths: (-got +want):\n%s", diff)
+	}
+	if !got["a"].Healthy() || got["b"].Healthy() {
+		t.Errorf("Healthy: got a=%v, b=%v; want true, false", got["a"].Healthy(), got["b"].Healthy())
+	}
+}
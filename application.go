@@ -0,0 +1,96 @@
+package nylas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Application contains the details of the Nylas application the client ID
+// belongs to.
+// See: https://docs.nylas.com/reference#application-management
+type Application struct {
+	Name         string   `json:"application_name"`
+	IconURL      string   `json:"icon_url"`
+	RedirectURIs []string `json:"redirect_uris"`
+}
+
+// UpdateApplicationRequest contains the request parameters required to update
+// an application, fields are optional and will overwrite previous values if
+// given.
+type UpdateApplicationRequest struct {
+	Name *string `json:"application_name,omitempty"`
+	// RedirectURIs to overwrite all previous redirect URIs with.
+	RedirectURIs *[]string `json:"redirect_uris,omitempty"`
+}
+
+// Application returns the details of the application.
+// See: https://docs.nylas.com/reference#get-application-details
+func (c *Client) Application(ctx context.Context) (Application, error) {
+	endpoint := fmt.Sprintf("/a/%s", c.clientID)
+	req, err := c.newAccountRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Application{}, err
+	}
+
+	var resp Application
+	return resp, c.do(req, &resp)
+}
+
+// UpdateApplication updates the details of the application.
+// See: https://docs.nylas.com/reference#update-application-details
+func (c *Client) UpdateApplication(
+	ctx context.Context, updateReq UpdateApplicationRequest,
+) (Application, error) {
+	endpoint := fmt.Sprintf("/a/%s", c.clientID)
+	req, err := c.newAccountRequest(ctx, http.MethodPost, endpoint, &updateReq)
+	if err != nil {
+		return Application{}, err
+	}
+
+	var resp Application
+	return resp, c.do(req, &resp)
+}
+
+// AddApplicationRedirectURIs adds redirect URIs to the application, keeping
+// the existing ones. URIs already present are not added again.
+func (c *Client) AddApplicationRedirectURIs(ctx context.Context, uris ...string) (Application, error) {
+	app, err := c.Application(ctx)
+	if err != nil {
+		return Application{}, err
+	}
+
+	redirectURIs := app.RedirectURIs
+	for _, uri := range uris {
+		if !containsString(redirectURIs, uri) {
+			redirectURIs = append(redirectURIs, uri)
+		}
+	}
+	return c.UpdateApplication(ctx, UpdateApplicationRequest{RedirectURIs: &redirectURIs})
+}
+
+// RemoveApplicationRedirectURIs removes redirect URIs from the application,
+// keeping the others.
+func (c *Client) RemoveApplicationRedirectURIs(ctx context.Context, uris ...string) (Application, error) {
+	app, err := c.Application(ctx)
+	if err != nil {
+		return Application{}, err
+	}
+
+	redirectURIs := []string{}
+	for _, uri := range app.RedirectURIs {
+		if !containsString(uris, uri) {
+			redirectURIs = append(redirectURIs, uri)
+		}
+	}
+	return c.UpdateApplication(ctx, UpdateApplicationRequest{RedirectURIs: &redirectURIs})
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
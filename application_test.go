@@ -0,0 +1,130 @@
+package nylas
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplication(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		assertMethodPath(t, r, http.MethodGet, fmt.Sprintf("/a/%s", clientID))
+
+		_, _ = w.Write(applicationJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	got, err := client.Application(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Application{
+		Name:         "My New App Name",
+		IconURL:      "https://inbox-developer-resources.s3.amazonaws.com/icons/da5b3a1c.png",
+		RedirectURIs: []string{"http://localhost:5555/login_callback", "localhost"},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Application: (-got +want):\n%s", diff)
+	}
+}
+
+func TestUpdateApplication(t *testing.T) {
+	clientID := "clientID"
+	clientSecret := "clientSecret"
+	wantBody := []byte(`{"application_name":"My New App Name"}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, clientSecret, "")
+		assertMethodPath(t, r, http.MethodPost, fmt.Sprintf("/a/%s", clientID))
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(applicationJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient(clientID, clientSecret, withTestServer(ts))
+	_, err := client.UpdateApplication(context.Background(), UpdateApplicationRequest{
+		Name: String("My New App Name"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestApplicationRedirectURIs(t *testing.T) {
+	tests := map[string]struct {
+		update   func(*Client) (Application, error)
+		wantBody string
+	}{
+		"add": {
+			update: func(c *Client) (Application, error) {
+				return c.AddApplicationRedirectURIs(context.Background(),
+					"localhost", "https://example.com/callback")
+			},
+			wantBody: `{"redirect_uris":["http://localhost:5555/login_callback",` +
+				`"localhost","https://example.com/callback"]}`,
+		},
+		"remove": {
+			update: func(c *Client) (Application, error) {
+				return c.RemoveApplicationRedirectURIs(context.Background(), "localhost")
+			},
+			wantBody: `{"redirect_uris":["http://localhost:5555/login_callback"]}`,
+		},
+		"remove all": {
+			update: func(c *Client) (Application, error) {
+				return c.RemoveApplicationRedirectURIs(context.Background(),
+					"localhost", "http://localhost:5555/login_callback")
+			},
+			wantBody: `{"redirect_uris":[]}`,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					body, err := ioutil.ReadAll(r.Body)
+					if err != nil {
+						t.Fatalf("failed to read request body: %v", err)
+					}
+					if diff := cmp.Diff(string(body), tt.wantBody); diff != "" {
+						t.Errorf("req body: (-got +want):\n%s", diff)
+					}
+				}
+				_, _ = w.Write(applicationJSON)
+			}))
+			defer ts.Close()
+
+			client := NewClient("clientID", "clientSecret", withTestServer(ts))
+			if _, err := tt.update(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+var applicationJSON = []byte(`{
+    "application_name": "My New App Name",
+    "icon_url": "https://inbox-developer-resources.s3.amazonaws.com/icons/da5b3a1c.png",
+    "redirect_uris": [
+        "http://localhost:5555/login_callback",
+        "localhost"
+    ]
+}`)
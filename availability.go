@@ -0,0 +1,252 @@
+package nylas
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EventStatus constants, for more info see:
+// https://docs.nylas.com/reference#event-object
+const (
+	EventStatusConfirmed = "confirmed"
+	EventStatusTentative = "tentative"
+	EventStatusCancelled = "cancelled"
+)
+
+// FreeSlotsRequest contains the parameters used by FreeSlots to find free
+// time slots without the Nylas availability API.
+type FreeSlotsRequest struct {
+	// Start and End of the window to search for free time slots.
+	Start time.Time
+	End   time.Time
+	// Duration of each free time slot.
+	Duration time.Duration
+	// Interval between the start of each returned time slot, defaults to
+	// Duration when zero.
+	Interval time.Duration
+	// Buffer required free before and after busy events.
+	Buffer time.Duration
+	// OpenHours restricts free time slots to fall entirely within any one of
+	// them, e.g. working hours. The Emails field is ignored.
+	OpenHours []OpenHours
+	// Calendars the events belong to, their TimeZone is used to place
+	// all-day events.
+	Calendars []Calendar
+	// Location used for all-day events on calendars without a known time
+	// zone, defaults to UTC.
+	Location *time.Location
+}
+
+// timeRange is a half open range of time [start, end).
+type timeRange struct {
+	start, end time.Time
+}
+
+// openWindow is OpenHours parsed into offsets from midnight.
+type openWindow struct {
+	days       map[time.Weekday]bool
+	loc        *time.Location
+	start, end time.Duration
+}
+
+// FreeSlots returns the time slots within the request window which do not
+// overlap any busy event.
+//
+// Events which are not busy or are cancelled are ignored. Events with a
+// single time block the instant they occur at, while dates and date spans
+// block whole days in the time zone of their calendar. Date spans include
+// their end date.
+func FreeSlots(events []Event, req FreeSlotsRequest) ([]TimeSlot, error) {
+	if req.Duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	if req.End.Before(req.Start) {
+		return nil, errors.New("end must not be before start")
+	}
+	interval := req.Interval
+	if interval == 0 {
+		interval = req.Duration
+	} else if interval < 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	windows, err := parseOpenHours(req.OpenHours)
+	if err != nil {
+		return nil, err
+	}
+	busy := busyRanges(events, req)
+
+	slots := []TimeSlot{}
+	i := 0
+	for start := req.Start; !start.Add(req.Duration).After(req.End); start = start.Add(interval) {
+		end := start.Add(req.Duration)
+
+		// Busy ranges are sorted and merged, so any ending at or before
+		// this slot starts also end before every later slot.
+		for i < len(busy) && !busy[i].end.After(start) {
+			i++
+		}
+		if i < len(busy) && busy[i].start.Before(end) {
+			continue
+		}
+		if !withinOpenHours(windows, start, end) {
+			continue
+		}
+
+		slots = append(slots, TimeSlot{
+			Object:    "time_slot",
+			Status:    TimeSlotStatusFree,
+			StartTime: start,
+			EndTime:   end,
+		})
+	}
+	return slots, nil
+}
+
+// busyRanges returns the sorted and merged ranges blocked by the events,
+// including the request buffer.
+func busyRanges(events []Event, req FreeSlotsRequest) []timeRange {
+	defaultLoc := req.Location
+	if defaultLoc == nil {
+		defaultLoc = time.UTC
+	}
+	calendarLocs := make(map[string]*time.Location, len(req.Calendars))
+	for _, cal := range req.Calendars {
+		if cal.TimeZone != nil && cal.TimeZone.Location != nil {
+			calendarLocs[cal.ID] = cal.TimeZone.Location
+		}
+	}
+
+	var ranges []timeRange
+	for _, e := range events {
+		if !e.Busy || e.Status == EventStatusCancelled {
+			continue
+		}
+		loc, ok := calendarLocs[e.CalendarID]
+		if !ok {
+			loc = defaultLoc
+		}
+		r, ok := eventRange(e.When, loc)
+		if !ok {
+			continue
+		}
+		if !r.end.After(r.start) {
+			// Block the instant so slots containing it are not free.
+			r.end = r.start.Add(time.Nanosecond)
+		}
+		r.start = r.start.Add(-req.Buffer)
+		r.end = r.end.Add(req.Buffer)
+		ranges = append(ranges, r)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Before(ranges[j].start)
+	})
+
+	var merged []timeRange
+	for _, r := range ranges {
+		n := len(merged)
+		if n > 0 && !r.start.After(merged[n-1].end) {
+			if r.end.After(merged[n-1].end) {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// eventRange returns the range of time blocked by the when subobject, with
+// dates placed at midnight in loc.
+func eventRange(when EventTimeSubobject, loc *time.Location) (timeRange, bool) {
+	midnight := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+
+	switch w := when.(type) {
+	case *EventTime:
+		return timeRange{start: w.Time, end: w.Time}, true
+	case *EventTimespan:
+		return timeRange{start: w.StartTime, end: w.EndTime}, true
+	case *EventDate:
+		start := midnight(w.Date)
+		return timeRange{start: start, end: start.AddDate(0, 0, 1)}, true
+	case *EventDatespan:
+		return timeRange{
+			start: midnight(w.StartDate),
+			end:   midnight(w.EndDate).AddDate(0, 0, 1),
+		}, true
+	}
+	return timeRange{}, false
+}
+
+func parseOpenHours(openHours []OpenHours) ([]openWindow, error) {
+	windows := make([]openWindow, 0, len(openHours))
+	for _, oh := range openHours {
+		start, err := parseClock(oh.Start)
+		if err != nil {
+			return nil, fmt.Errorf("open hours start: %w", err)
+		}
+		end, err := parseClock(oh.End)
+		if err != nil {
+			return nil, fmt.Errorf("open hours end: %w", err)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("open hours end %s must be after start %s", oh.End, oh.Start)
+		}
+
+		w := openWindow{loc: oh.Timezone, start: start, end: end}
+		if w.loc == nil {
+			w.loc = time.UTC
+		}
+		if len(oh.Days) > 0 {
+			w.days = make(map[time.Weekday]bool, len(oh.Days))
+			for _, d := range oh.Days {
+				w.days[d] = true
+			}
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// parseClock parses a "15:04" formatted time into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// withinOpenHours reports whether [start, end) falls entirely within any of
+// the windows, which is always true when there are none.
+func withinOpenHours(windows []openWindow, start, end time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		local := start.In(w.loc)
+		if w.days != nil && !w.days[local.Weekday()] {
+			continue
+		}
+		// Use time.Date rather than adding to midnight so the window
+		// keeps its wall clock times across DST transitions.
+		y, m, d := local.Date()
+		wStart := wallClock(y, m, d, w.start, w.loc)
+		wEnd := wallClock(y, m, d, w.end, w.loc)
+		if !start.Before(wStart) && !end.After(wEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock returns the time offset from midnight on the given date in loc,
+// as read on a wall clock.
+func wallClock(y int, m time.Month, d int, offset time.Duration, loc *time.Location) time.Time {
+	return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, loc)
+}
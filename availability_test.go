@@ -0,0 +1,154 @@
+package nylas
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFreeSlots(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 3, 2, hour, min, 0, 0, time.UTC)
+	}
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	timespan := func(start, end time.Time) *EventTimespan {
+		return &EventTimespan{StartTime: start, EndTime: end}
+	}
+	starts := func(slots []TimeSlot) []time.Time {
+		var got []time.Time
+		for _, s := range slots {
+			got = append(got, s.StartTime)
+		}
+		return got
+	}
+
+	tests := map[string]struct {
+		events []Event
+		req    FreeSlotsRequest
+		want   []time.Time
+	}{
+		"busy timespan": {
+			events: []Event{
+				{Busy: true, When: timespan(at(10, 0), at(11, 0))},
+				{Busy: false, When: timespan(at(9, 0), at(10, 0))},
+				{Busy: true, Status: EventStatusCancelled, When: timespan(at(11, 0), at(12, 0))},
+			},
+			req: FreeSlotsRequest{
+				Start: at(9, 0), End: at(12, 0), Duration: time.Hour, Interval: 30 * time.Minute,
+			},
+			want: []time.Time{at(9, 0), at(11, 0)},
+		},
+		"buffer": {
+			events: []Event{{Busy: true, When: timespan(at(10, 0), at(11, 0))}},
+			req: FreeSlotsRequest{
+				Start: at(9, 0), End: at(12, 0), Duration: 30 * time.Minute, Buffer: 15 * time.Minute,
+			},
+			want: []time.Time{at(9, 0), at(11, 30)},
+		},
+		"overlapping events": {
+			events: []Event{
+				{Busy: true, When: timespan(at(9, 0), at(10, 30))},
+				{Busy: true, When: timespan(at(9, 30), at(10, 0))},
+				{Busy: true, When: timespan(at(10, 30), at(11, 0))},
+			},
+			req:  FreeSlotsRequest{Start: at(9, 0), End: at(12, 0), Duration: 30 * time.Minute},
+			want: []time.Time{at(11, 0), at(11, 30)},
+		},
+		"time": {
+			events: []Event{{Busy: true, When: &EventTime{Time: at(10, 0)}}},
+			req:    FreeSlotsRequest{Start: at(9, 0), End: at(11, 0), Duration: 30 * time.Minute},
+			want:   []time.Time{at(9, 0), at(9, 30), at(10, 30)},
+		},
+		"date in calendar timezone": {
+			events: []Event{{Busy: true, CalendarID: "ny", When: &EventDate{Date: date(2020, 3, 2)}}},
+			req: FreeSlotsRequest{
+				Start:     time.Date(2020, 3, 2, 4, 0, 0, 0, time.UTC),
+				End:       time.Date(2020, 3, 3, 6, 0, 0, 0, time.UTC),
+				Duration:  time.Hour,
+				Interval:  time.Hour,
+				Calendars: []Calendar{{ID: "ny", TimeZone: &TimeZone{Location: ny}}},
+			},
+			want: []time.Time{
+				time.Date(2020, 3, 2, 4, 0, 0, 0, time.UTC),
+				time.Date(2020, 3, 3, 5, 0, 0, 0, time.UTC),
+			},
+		},
+		"datespan includes end date": {
+			events: []Event{{
+				Busy: true,
+				When: &EventDatespan{StartDate: date(2020, 3, 2), EndDate: date(2020, 3, 3)},
+			}},
+			req: FreeSlotsRequest{
+				Start:    date(2020, 3, 1),
+				End:      date(2020, 3, 6),
+				Duration: 24 * time.Hour,
+			},
+			want: []time.Time{date(2020, 3, 1), date(2020, 3, 4), date(2020, 3, 5)},
+		},
+		"open hours across DST": {
+			req: FreeSlotsRequest{
+				Start:    time.Date(2020, 3, 6, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC),
+				Duration: time.Hour,
+				OpenHours: []OpenHours{{
+					Days:     []time.Weekday{time.Monday, time.Friday},
+					Timezone: ny,
+					Start:    "09:00",
+					End:      "10:00",
+				}},
+			},
+			want: []time.Time{
+				time.Date(2020, 3, 6, 14, 0, 0, 0, time.UTC), // Friday EST
+				time.Date(2020, 3, 9, 13, 0, 0, 0, time.UTC), // Monday EDT
+			},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, err := FreeSlots(tt.events, tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(starts(got), tt.want); diff != "" {
+				t.Errorf("FreeSlots: (-got +want):\n%s", diff)
+			}
+			for _, s := range got {
+				if s.Status != TimeSlotStatusFree || !s.EndTime.Equal(s.StartTime.Add(tt.req.Duration)) {
+					t.Errorf("unexpected slot: %+v", s)
+				}
+			}
+		})
+	}
+}
+
+func TestFreeSlotsInvalid(t *testing.T) {
+	start := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := map[string]FreeSlotsRequest{
+		"no duration": {Start: start, End: start.Add(time.Hour)},
+		"end before":  {Start: start, End: start.Add(-time.Hour), Duration: time.Hour},
+		"bad open hours": {
+			Start: start, End: start, Duration: time.Hour,
+			OpenHours: []OpenHours{{Start: "9am", End: "17:00"}},
+		},
+		"empty open hours": {
+			Start: start, End: start, Duration: time.Hour,
+			OpenHours: []OpenHours{{Start: "10:00", End: "09:00"}},
+		},
+	}
+
+	for desc, req := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := FreeSlots(nil, req); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
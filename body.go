@@ -0,0 +1,317 @@
+package nylas
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// quoteClasses are the classes of elements containing quoted history
+	// or signatures added by common mail clients.
+	quoteClasses = map[string]bool{
+		"gmail_quote":          true,
+		"gmail_signature":      true,
+		"yahoo_quoted":         true,
+		"moz-cite-prefix":      true,
+		"moz-signature":        true,
+		"protonmail_quote":     true,
+		"nylas_quote":          true,
+		"nylas_forward":        true,
+		"OutlookMessageHeader": true,
+	}
+	// quoteIDs are the ids of elements which start the quoted history in
+	// Outlook, everything from them onwards is removed.
+	quoteIDs = map[string]bool{
+		"divRplyFwdMsg": true,
+		"appendonsend":  true,
+		"stopSpelling":  true,
+	}
+	// signatureIDs are the ids of signature elements.
+	signatureIDs = map[string]bool{
+		"Signature":                   true,
+		"signature":                   true,
+		"ms-outlook-mobile-signature": true,
+	}
+
+	attributionLine = regexp.MustCompile(
+		`(?i)^(on|am|le|el|il|op)\s.{0,300}(wrote|schrieb|a écrit|escribió|ha scritto|schreef)\s*:$`)
+	originalMessageLine = regexp.MustCompile(
+		`(?i)^(-{2,}\s*(original message|forwarded message)\s*-{2,}|_{10,})$`)
+	headerFromLine   = regexp.MustCompile(`(?i)^\*?from:\*?\s`)
+	headerSentLine   = regexp.MustCompile(`(?i)^\*?(sent|date|to|subject):\*?\s`)
+	signatureLine    = regexp.MustCompile(`^--\s?$`)
+	mobileSignature  = regexp.MustCompile(`(?i)^(sent from my \S|sent from (mail|outlook|yahoo mail) for|get outlook for (ios|android))`)
+	multipleNewlines = regexp.MustCompile(`\n{3,}`)
+)
+
+// ExtractReply returns only the new content of a reply message body, as
+// plain text.
+//
+// HTML bodies are converted to text with HTMLToText after removing the quoted
+// history and signature elements added by common mail clients such as Gmail,
+// Outlook, Apple Mail and Thunderbird. The text is then passed through
+// StripQuotedText and StripSignature.
+func ExtractReply(body string) string {
+	text := body
+	if isHTML(body) {
+		text = htmlToText(body, true)
+	}
+	return StripSignature(StripQuotedText(text))
+}
+
+// ReplyText returns only the new content of the message body, as plain text.
+// See ExtractReply.
+func (m Message) ReplyText() string {
+	return ExtractReply(m.Body)
+}
+
+// HTMLToText converts an HTML body to plain text, dropping all markup,
+// scripts and styles.
+func HTMLToText(body string) string {
+	return htmlToText(body, false)
+}
+
+// StripQuotedText removes quoted history from a plain text body, that is
+// lines starting with ">" and everything from the first attribution line such
+// as "On ... wrote:", original message separator or Outlook style header block.
+func StripQuotedText(text string) string {
+	lines := splitLines(text)
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if isQuoteStart(lines, i) {
+			break
+		}
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return cleanText(strings.Join(kept, "\n"))
+}
+
+// StripSignature removes the signature from a plain text body, that is
+// everything from a "-- " delimiter line or a mobile "Sent from my ..." line.
+func StripSignature(text string) string {
+	lines := splitLines(text)
+	for i, line := range lines {
+		if signatureLine.MatchString(line) || mobileSignature.MatchString(strings.TrimSpace(line)) {
+			lines = lines[:i]
+			break
+		}
+	}
+	return cleanText(strings.Join(lines, "\n"))
+}
+
+func splitLines(text string) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	return strings.Split(text, "\n")
+}
+
+// isQuoteStart reports whether the quoted history starts at lines[i].
+func isQuoteStart(lines []string, i int) bool {
+	line := strings.TrimSpace(lines[i])
+	if line == "" {
+		return false
+	}
+	if attributionLine.MatchString(line) || originalMessageLine.MatchString(line) {
+		return true
+	}
+	// Attribution lines are often wrapped by the sending client.
+	if i+1 < len(lines) && attributionLine.MatchString(line+" "+strings.TrimSpace(lines[i+1])) {
+		return true
+	}
+	// Outlook header blocks, e.g. "From: ...", "Sent: ...", "To: ...".
+	if headerFromLine.MatchString(line) {
+		headers := 0
+		for j := i + 1; j < len(lines) && j <= i+4; j++ {
+			if headerSentLine.MatchString(strings.TrimSpace(lines[j])) {
+				headers++
+			}
+		}
+		return headers >= 2
+	}
+	return false
+}
+
+// cleanText trims trailing whitespace from each line, collapses runs of blank
+// lines and trims leading and trailing blank lines.
+func cleanText(text string) string {
+	lines := splitLines(text)
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\u00a0")
+	}
+	text = multipleNewlines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.Trim(text, "\n")
+}
+
+// htmlToText converts an HTML body to plain text, removing quoted history and
+// signature elements when stripQuotes is set.
+func htmlToText(body string, stripQuotes bool) string {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		// The parser only fails on read errors, which strings.Reader
+		// never returns.
+		return body
+	}
+	if stripQuotes {
+		removeQuotes(doc)
+	}
+
+	w := &textWriter{}
+	w.node(doc)
+	return cleanText(w.b.String())
+}
+
+// removeQuotes removes quoted history and signature elements from n.
+func removeQuotes(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			id := htmlAttr(c, "id")
+			switch {
+			case quoteIDs[id]:
+				// Outlook places the quoted history after the
+				// header, so remove everything that follows.
+				for c != nil {
+					next = c.NextSibling
+					n.RemoveChild(c)
+					c = next
+				}
+				return
+			case c.DataAtom == atom.Blockquote || signatureIDs[id] || hasQuoteClass(c):
+				n.RemoveChild(c)
+			default:
+				removeQuotes(c)
+			}
+		}
+		c = next
+	}
+}
+
+func hasQuoteClass(n *html.Node) bool {
+	for _, class := range strings.Fields(htmlAttr(n, "class")) {
+		if quoteClasses[class] {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textWriter writes the text content of HTML nodes.
+type textWriter struct {
+	b   strings.Builder
+	pre int
+}
+
+// newlines ensures the text written so far ends with at least n newlines,
+// unless nothing has been written.
+func (w *textWriter) newlines(n int) {
+	s := w.b.String()
+	if s == "" {
+		return
+	}
+	for i := len(s) - 1; i >= 0 && n > 0 && s[i] == '\n'; i-- {
+		n--
+	}
+	w.b.WriteString(strings.Repeat("\n", n))
+}
+
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		w.b.WriteString(s)
+		return
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			w.space()
+		}
+		return
+	}
+	if first, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(first) {
+		w.space()
+	}
+	w.b.WriteString(strings.Join(fields, " "))
+	if last, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(last) {
+		w.b.WriteByte(' ')
+	}
+}
+
+// space writes a single space unless the text already ends in whitespace.
+func (w *textWriter) space() {
+	s := w.b.String()
+	if s != "" && s[len(s)-1] != ' ' && s[len(s)-1] != '\n' {
+		w.b.WriteByte(' ')
+	}
+}
+
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.CommentNode, html.DoctypeNode:
+		return
+	case html.ElementNode:
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Head, atom.Title, atom.Noscript, atom.Template:
+			return
+		case atom.Br:
+			w.b.WriteByte('\n')
+			return
+		case atom.Hr:
+			w.newlines(1)
+			return
+		case atom.Img:
+			if alt := htmlAttr(n, "alt"); alt != "" {
+				w.text(alt)
+			}
+			return
+		}
+	}
+
+	var after int
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Blockquote, atom.Pre, atom.Table, atom.Ul, atom.Ol, atom.Dl:
+		w.newlines(2)
+		after = 2
+	case atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Address, atom.Tr, atom.Dt, atom.Dd, atom.Center:
+		w.newlines(1)
+		after = 1
+	case atom.Li:
+		w.newlines(1)
+		w.b.WriteString("- ")
+		after = 1
+	case atom.Td, atom.Th:
+		w.space()
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+	if n.DataAtom == atom.Pre {
+		w.pre--
+	}
+	if after > 0 {
+		w.newlines(after)
+	}
+}
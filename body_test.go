@@ -0,0 +1,87 @@
+package nylas
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractReply(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"gmail": {
+			body: `<div dir="ltr">Thanks, that fixed it!<div><br></div><div>Cheers</div></div>` +
+				`<br><div class="gmail_quote"><div dir="ltr" class="gmail_attr">On Mon, Mar 2, 2020 at 9:00 AM ` +
+				`Support &lt;support@example.com&gt; wrote:<br></div><blockquote class="gmail_quote">` +
+				`Have you tried turning it off and on again?</blockquote></div>`,
+			want: "Thanks, that fixed it!\n\nCheers",
+		},
+		"gmail signature": {
+			body: `<div dir="ltr">Sounds good.<br clear="all"><div><br></div>-- <br>` +
+				`<div dir="ltr" class="gmail_signature">Alice<br>ACME Inc.</div></div>`,
+			want: "Sounds good.",
+		},
+		"outlook": {
+			body: `<html><head><style>p {margin:0}</style></head><body>` +
+				`<div style="font-family: Calibri">Please close the ticket.</div>` +
+				`<div id="Signature"><p>Bob Smith<br>Manager</p></div>` +
+				`<hr style="display:inline-block;width:98%" tabindex="-1">` +
+				`<div id="divRplyFwdMsg" dir="ltr"><b>From:</b> Support<br><b>Sent:</b> Monday</div>` +
+				`<div>Is there anything else?</div></body></html>`,
+			want: "Please close the ticket.",
+		},
+		"apple mail": {
+			body: `<html><body>Yes, <b>tomorrow</b>&nbsp;works.<br><div><br>` +
+				`<blockquote type="cite"><div>On 2 Mar 2020, at 09:00, Support &lt;support@example.com&gt; wrote:</div>` +
+				`<div>Can we call?</div></blockquote></div><div>On 2 Mar 2020, at 09:00, Support ` +
+				`&lt;support@example.com&gt; wrote:</div></body></html>`,
+			want: "Yes, tomorrow works.",
+		},
+		"html list and script": {
+			body: "<p>Steps:</p><ul><li>one</li><li>two</li></ul><script>alert(1)</script><pre>a  b\n c</pre>",
+			want: "Steps:\n\n- one\n- two\n\na  b\n c",
+		},
+		"text": {
+			body: "I'll be there.\r\n\r\nOn Mon, Mar 2, 2020 at 9:00 AM Support <support@example.com>\r\n" +
+				"wrote:\r\n> Will you attend?\r\n",
+			want: "I'll be there.",
+		},
+		"text inline quotes": {
+			body: "> Question one?\nAnswer one.\n> Question two?\nAnswer two.\n\n--\nAlice\n",
+			want: "Answer one.\nAnswer two.",
+		},
+		"text outlook header": {
+			body: "Done.\n\nSent from my iPhone\n\nFrom: Support\nSent: Monday\nTo: Alice\nSubject: Ticket\n",
+			want: "Done.",
+		},
+		"text original message": {
+			body: "Approved.\n\n-----Original Message-----\nFrom: Bob\nPlease approve.",
+			want: "Approved.",
+		},
+		"text from line": {
+			body: "From: my desk, where I'm stuck.\nHelp!",
+			want: "From: my desk, where I'm stuck.\nHelp!",
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got := ExtractReply(tt.body)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ExtractReply: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	body := `<p>Hi&nbsp;<a href="https://example.com">there</a>,</p>` +
+		`<blockquote>quoted</blockquote><table><tr><td>a</td><td>b</td></tr></table><img alt="logo">`
+	got := HTMLToText(body)
+	want := "Hi there,\n\nquoted\n\na b\n\nlogo"
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("HTMLToText: (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,301 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// BulkRecipient is a recipient of a bulk send with the data used to render
+// their message.
+type BulkRecipient struct {
+	// ID uniquely identifies the recipient within the bulk send, it is used
+	// to record results and resume. Defaults to the first To email address.
+	ID   string
+	To   []Participant
+	Data map[string]interface{}
+}
+
+func (r BulkRecipient) id() string {
+	if r.ID != "" || len(r.To) == 0 {
+		return r.ID
+	}
+	return strings.ToLower(r.To[0].Email)
+}
+
+// BulkResult is the outcome of sending to a BulkRecipient.
+type BulkResult struct {
+	RecipientID string `json:"recipient_id"`
+	// MessageID of the sent message, empty if sending failed.
+	MessageID string    `json:"message_id,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+	// Error is the message of the error rendering or sending the message,
+	// empty if it was sent.
+	Error string `json:"error,omitempty"`
+	// StatusCode is the HTTP status code of the API error sending the
+	// message, zero if it was sent or failed for another reason such as
+	// missing template variables.
+	StatusCode int `json:"status_code,omitempty"`
+	// Err is the error rendering or sending the message, such as an *Error
+	// or *MissingVariablesError. It is not persisted so is nil for results
+	// loaded from a BulkResultStore.
+	Err error `json:"-"`
+}
+
+// Sent reports whether the message was sent.
+func (r BulkResult) Sent() bool {
+	return r.MessageID != ""
+}
+
+// BulkResultStore records the results of a bulk send so it can be resumed.
+type BulkResultStore interface {
+	// BulkResult returns the result recorded for the recipient with the
+	// given ID, ok is false if there is none.
+	BulkResult(ctx context.Context, recipientID string) (result BulkResult, ok bool, err error)
+	// SetBulkResult records a result, replacing any previous result for the
+	// same recipient.
+	SetBulkResult(ctx context.Context, result BulkResult) error
+}
+
+// MemoryBulkResultStore is a BulkResultStore which keeps results in memory.
+type MemoryBulkResultStore struct {
+	mu      sync.RWMutex
+	results map[string]BulkResult
+}
+
+// NewMemoryBulkResultStore returns a new empty MemoryBulkResultStore.
+func NewMemoryBulkResultStore() *MemoryBulkResultStore {
+	return &MemoryBulkResultStore{results: make(map[string]BulkResult)}
+}
+
+// BulkResult implements the BulkResultStore interface.
+func (s *MemoryBulkResultStore) BulkResult(_ context.Context, recipientID string) (BulkResult, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result, ok := s.results[recipientID]
+	return result, ok, nil
+}
+
+// SetBulkResult implements the BulkResultStore interface.
+func (s *MemoryBulkResultStore) SetBulkResult(_ context.Context, result BulkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[result.RecipientID] = result
+	return nil
+}
+
+// FileBulkResultStore is a BulkResultStore which keeps results in a JSON
+// file, so a bulk send can be resumed after a restart. Each result recorded
+// rewrites the file, so a store should be used for a single bulk send rather
+// than kept for every send made.
+type FileBulkResultStore struct {
+	mu      sync.RWMutex
+	results *fileMap[BulkResult]
+}
+
+// NewFileBulkResultStore returns a new FileBulkResultStore using the file at
+// path, which is created on the first change if it does not exist.
+func NewFileBulkResultStore(path string) (*FileBulkResultStore, error) {
+	results, err := loadFileMap[BulkResult](path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBulkResultStore{results: results}, nil
+}
+
+// BulkResult implements the BulkResultStore interface.
+func (s *FileBulkResultStore) BulkResult(_ context.Context, recipientID string) (BulkResult, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result, ok := s.results.m[recipientID]
+	return result, ok, nil
+}
+
+// SetBulkResult implements the BulkResultStore interface.
+func (s *FileBulkResultStore) SetBulkResult(_ context.Context, result BulkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results.set(result.RecipientID, result)
+}
+
+// BulkSendOptions provides optional parameters to NewBulkSender.
+type BulkSendOptions struct {
+	// Concurrency is the number of messages sent at the same time, defaults
+	// to one.
+	Concurrency int
+	// Interval is the minimum time between starting to send each message,
+	// limiting the rate messages are sent from the account.
+	Interval time.Duration
+	// Store records results so a bulk send can be resumed, recipients with
+	// a sent result are skipped. A message sent just before a crash may not
+	// have been recorded, and so is sent again when resuming.
+	Store BulkResultStore
+}
+
+// BulkSender sends a DraftTemplate personalized for many recipients with
+// SendDirectly.
+type BulkSender struct {
+	client   *Client
+	template *DraftTemplate
+	opts     BulkSendOptions
+}
+
+// NewBulkSender returns a new BulkSender sending from the account the client
+// is authenticated as.
+func NewBulkSender(client *Client, template *DraftTemplate, opts *BulkSendOptions) *BulkSender {
+	s := &BulkSender{client: client, template: template}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Concurrency < 1 {
+		s.opts.Concurrency = 1
+	}
+	return s
+}
+
+// Send renders and sends a message to each recipient, returning their
+// results in order.
+//
+// Errors rendering or sending a single message are recorded in its result,
+// and a message already sent is recorded even if the send is stopped. If the
+// Store fails the send stops, but the results of messages already sent are
+// still returned.
+// Account level errors, such as invalid credentials or the sending quota
+// being exceeded, stop the send and are returned wrapped, results are then
+// only returned for the recipients processed.
+func (s *BulkSender) Send(ctx context.Context, recipients []BulkRecipient) ([]BulkResult, error) {
+	ids := make(map[string]bool, len(recipients))
+	for i, r := range recipients {
+		id := r.id()
+		if id == "" {
+			return nil, fmt.Errorf("recipient %d has no id", i)
+		}
+		if ids[id] {
+			return nil, fmt.Errorf("duplicate recipient id %s", id)
+		}
+		ids[id] = true
+	}
+
+	results := make([]*BulkResult, len(recipients))
+	var pending []int
+	for i, r := range recipients {
+		if s.opts.Store != nil {
+			result, ok, err := s.opts.Store.BulkResult(ctx, r.id())
+			if err != nil {
+				return nil, err
+			}
+			if ok && result.Sent() {
+				results[i] = &result
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	jobs := make(chan int)
+	g.Go(func() error {
+		defer close(jobs)
+		var limit <-chan time.Time
+		if s.opts.Interval > 0 {
+			ticker := time.NewTicker(s.opts.Interval)
+			defer ticker.Stop()
+			limit = ticker.C
+		}
+		for n, i := range pending {
+			if n > 0 && limit != nil {
+				select {
+				case <-limit:
+				case <-gctx.Done():
+					return nil
+				}
+			}
+			select {
+			case jobs <- i:
+			case <-gctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+
+	for w := 0; w < s.opts.Concurrency; w++ {
+		g.Go(func() error {
+			for i := range jobs {
+				result, err := s.send(gctx, recipients[i])
+				if result.Sent() || err == nil {
+					// Sent messages are returned even if recording
+					// them failed.
+					results[i] = &result
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	var sent []BulkResult
+	for _, r := range results {
+		if r != nil {
+			sent = append(sent, *r)
+		}
+	}
+	return sent, err
+}
+
+// send renders and sends the message to r, returning an error only if the
+// bulk send should stop.
+func (s *BulkSender) send(ctx context.Context, r BulkRecipient) (BulkResult, error) {
+	result := BulkResult{RecipientID: r.id()}
+	draft, err := s.template.Render(r.To, r.Data)
+	if err == nil {
+		var msg Message
+		msg, err = s.client.SendDirectly(ctx, draft.DraftRequest)
+		switch {
+		case err == nil:
+			result.MessageID = msg.ID
+			result.SentAt = time.Now()
+		case ctx.Err() != nil:
+			// Stopped by another error or the caller, the result of
+			// this message is unknown.
+			return result, ctx.Err()
+		case isAccountError(err):
+			return result, fmt.Errorf("bulk send stopped at recipient %s: %w", result.RecipientID, err)
+		}
+	}
+	if err != nil {
+		result.Err = err
+		result.Error = err.Error()
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			result.StatusCode = apiErr.StatusCode
+		}
+	}
+
+	if s.opts.Store != nil {
+		// The message may have been sent after the bulk send was stopped,
+		// which must still be recorded so resuming does not send it again.
+		if err := s.opts.Store.SetBulkResult(context.WithoutCancel(ctx), result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// isAccountError reports whether err affects all messages sent from the
+// account rather than a single message.
+func isAccountError(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+}
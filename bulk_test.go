@@ -0,0 +1,300 @@
+package nylas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestBulkSender(t *testing.T) {
+	accessToken := "accessToken"
+	var mu sync.Mutex
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/send")
+
+		var req DraftRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+			return
+		}
+		email := req.To[0].Email
+		switch email {
+		case "rejected@example.com":
+			w.WriteHeader(http.StatusPaymentRequired)
+			_, _ = w.Write([]byte(`{"message": "Message rejected", "type": "api_error"}`))
+			return
+		case "quota@example.com":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "Quota exceeded", "type": "api_error"}`))
+			return
+		}
+		if want := "Hi " + req.To[0].Name; req.Subject != want {
+			t.Errorf("unexpected subject: %q", req.Subject)
+		}
+
+		mu.Lock()
+		sent = append(sent, email)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(Message{ID: "msg-" + email})
+	}))
+	defer ts.Close()
+
+	tmpl, err := NewDraftTemplate(DraftTemplateConfig{
+		Subject:  "Hi {{.Name}}",
+		TextBody: "Hello",
+		Required: []string{"Name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recipient := func(name, email string) BulkRecipient {
+		return BulkRecipient{
+			To:   []Participant{{Name: name, Email: email}},
+			Data: map[string]interface{}{"Name": name},
+		}
+	}
+	recipients := []BulkRecipient{
+		recipient("A", "a@example.com"),
+		recipient("", "missing@example.com"),
+		recipient("R", "rejected@example.com"),
+		recipient("B", "b@example.com"),
+	}
+
+	store := NewMemoryBulkResultStore()
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	sender := NewBulkSender(client, tmpl, &BulkSendOptions{
+		Concurrency: 2,
+		Interval:    time.Millisecond,
+		Store:       store,
+	})
+
+	got, err := sender.Send(context.Background(), recipients)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []BulkResult{
+		{RecipientID: "a@example.com", MessageID: "msg-a@example.com"},
+		{RecipientID: "missing@example.com", Error: "missing template variables: Name"},
+		{
+			RecipientID: "rejected@example.com",
+			Error: (&Error{
+				StatusCode: http.StatusPaymentRequired,
+				Body:       []byte(`{"message": "Message rejected", "type": "api_error"}`),
+				Message:    "Message rejected",
+				Type:       "api_error",
+			}).Error(),
+			StatusCode: http.StatusPaymentRequired,
+		},
+		{RecipientID: "b@example.com", MessageID: "msg-b@example.com"},
+	}
+	if diff := cmp.Diff(got, want, cmpopts.IgnoreFields(BulkResult{}, "SentAt", "Err")); diff != "" {
+		t.Errorf("Send: (-got +want):\n%s", diff)
+	}
+	var missing *MissingVariablesError
+	if !errors.As(got[1].Err, &missing) {
+		t.Errorf("expected *MissingVariablesError, got: %v", got[1].Err)
+	}
+	if _, ok := got[2].Err.(*Error); !ok {
+		t.Errorf("expected *Error, got: %v", got[2].Err)
+	}
+
+	// Resuming only sends to recipients without a sent result, and stops on
+	// account level errors.
+	recipients = append(recipients, recipient("Q", "quota@example.com"), recipient("C", "c@example.com"))
+	sender = NewBulkSender(client, tmpl, &BulkSendOptions{Store: store})
+	got, err = sender.Send(context.Background(), recipients)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected quota error, got: %v", err)
+	}
+	if len(got) != 4 {
+		t.Errorf("unexpected results: %+v", got)
+	}
+	if _, ok, _ := store.BulkResult(context.Background(), "c@example.com"); ok {
+		t.Error("unexpected result after account error")
+	}
+	if diff := cmp.Diff(sent, []string{"a@example.com", "b@example.com"}, cmpopts.SortSlices(func(a, b string) bool {
+		return a < b
+	})); diff != "" {
+		t.Errorf("sent: (-got +want):\n%s", diff)
+	}
+}
+
+// waitForCancelTransport delays successful responses until the request
+// context is cancelled, so they complete after the bulk send was stopped.
+type waitForCancelTransport struct{}
+
+func (waitForCancelTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+	return resp, nil
+}
+
+func TestBulkSenderSentWhileStopped(t *testing.T) {
+	// The quota error is only returned once the other message is being sent.
+	sending := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req DraftRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+			return
+		}
+		if req.To[0].Email == "quota@example.com" {
+			<-sending
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "Quota exceeded", "type": "api_error"}`))
+			return
+		}
+		close(sending)
+		_ = json.NewEncoder(w).Encode(Message{ID: "msg-" + req.To[0].Email})
+	}))
+	defer ts.Close()
+
+	tmpl, err := NewDraftTemplate(DraftTemplateConfig{Subject: "Hi", TextBody: "Hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := NewMemoryBulkResultStore()
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"),
+		WithHTTPClient(&http.Client{Transport: waitForCancelTransport{}}))
+	sender := NewBulkSender(client, tmpl, &BulkSendOptions{Concurrency: 2, Store: store})
+
+	got, err := sender.Send(context.Background(), []BulkRecipient{
+		{To: []Participant{{Email: "a@example.com"}}},
+		{To: []Participant{{Email: "quota@example.com"}}},
+	})
+	if !hasStatusCode(err, http.StatusTooManyRequests) {
+		t.Fatalf("expected quota error, got: %v", err)
+	}
+	want := []BulkResult{{RecipientID: "a@example.com", MessageID: "msg-a@example.com"}}
+	if diff := cmp.Diff(got, want, cmpopts.IgnoreFields(BulkResult{}, "SentAt", "Err")); diff != "" {
+		t.Errorf("Send: (-got +want):\n%s", diff)
+	}
+	result, ok, _ := store.BulkResult(context.Background(), "a@example.com")
+	if !ok || !result.Sent() {
+		t.Errorf("sent message not recorded: %+v", result)
+	}
+}
+
+// failingBulkResultStore is a BulkResultStore which fails to record results.
+type failingBulkResultStore struct {
+	*MemoryBulkResultStore
+}
+
+func (*failingBulkResultStore) SetBulkResult(context.Context, BulkResult) error {
+	return errStoreUnavailable
+}
+
+func TestBulkSenderStoreError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Message{ID: "msg"})
+	}))
+	defer ts.Close()
+
+	tmpl, err := NewDraftTemplate(DraftTemplateConfig{Subject: "Hi", TextBody: "Hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := &failingBulkResultStore{NewMemoryBulkResultStore()}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	sender := NewBulkSender(client, tmpl, &BulkSendOptions{Store: store})
+
+	got, err := sender.Send(context.Background(), []BulkRecipient{
+		{To: []Participant{{Email: "a@example.com"}}},
+		{To: []Participant{{Email: "b@example.com"}}},
+	})
+	if err != errStoreUnavailable {
+		t.Fatalf("expected store error, got: %v", err)
+	}
+	// The message was sent so is returned even though it was not recorded.
+	if len(got) != 1 || got[0].RecipientID != "a@example.com" || !got[0].Sent() {
+		t.Errorf("unexpected results: %+v", got)
+	}
+}
+
+func TestFileBulkResultStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nylas")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "results.json")
+
+	ctx := context.Background()
+	s, err := NewFileBulkResultStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := []BulkResult{
+		{RecipientID: "a", MessageID: "m1", SentAt: time.Unix(1600000000, 0).UTC()},
+		{RecipientID: "b", Error: "402: Message rejected", StatusCode: http.StatusPaymentRequired},
+	}
+	for _, r := range results {
+		if err := s.SetBulkResult(ctx, r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	s, err = NewFileBulkResultStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range results {
+		got, ok, err := s.BulkResult(ctx, want.RecipientID)
+		if err != nil || !ok {
+			t.Fatalf("result %s: ok %v, err %v", want.RecipientID, ok, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("BulkResult: (-got +want):\n%s", diff)
+		}
+	}
+	if _, ok, _ := s.BulkResult(ctx, "c"); ok {
+		t.Error("unexpected result")
+	}
+}
+
+func TestBulkSenderInvalidRecipients(t *testing.T) {
+	tmpl, err := NewDraftTemplate(DraftTemplateConfig{Subject: "Hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sender := NewBulkSender(NewClient("", ""), tmpl, nil)
+	tests := map[string][]BulkRecipient{
+		"no id":     {{}},
+		"duplicate": {{To: []Participant{{Email: "a@example.com"}}}, {ID: "a@example.com"}},
+	}
+	for desc, recipients := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := sender.Send(context.Background(), recipients); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
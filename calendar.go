@@ -2,6 +2,8 @@ package nylas
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -33,13 +35,33 @@ type Calendar struct {
 	JobStatusID string `json:"job_status_id"`
 	// True if the Calendar is read only
 	ReadOnly bool `json:"read_only"`
+	// Geographic location of the Calendar as free-form text.
+	Location string `json:"location"`
 	// IANA time zone database formatted string (e.g. America/New_York).
 	TimeZone *TimeZone `json:"timezone"`
+	// Key-value pairs stored on the Calendar.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (tz TimeZone) MarshalJSON() ([]byte, error) {
+	if tz.Location == nil {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(tz.Location.String())), nil
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
-func (tz *TimeZone) UnmarshalJSON(b []byte) (err error) {
-	s, _ := strconv.Unquote(string(b))
+func (tz *TimeZone) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		tz.Location = nil
+		return nil
+	}
+
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return fmt.Errorf("timezone must be a string: %s", b)
+	}
 	loc, err := time.LoadLocation(s)
 	if err != nil {
 		return err
@@ -85,3 +107,249 @@ func (c *Client) Calendar(ctx context.Context, id string) (Calendar, error) {
 	var resp Calendar
 	return resp, c.do(req, &resp)
 }
+
+// CalendarRequest contains the request parameters required to create a
+// calendar.
+// See: https://developer.nylas.com/docs/api/#post/calendars
+type CalendarRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	TimeZone    *TimeZone         `json:"timezone,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// UpdateCalendarRequest contains the request parameters required to update a
+// calendar, fields are optional and will overwrite previous values if given.
+type UpdateCalendarRequest struct {
+	Name        *string            `json:"name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	Location    *string            `json:"location,omitempty"`
+	TimeZone    *TimeZone          `json:"timezone,omitempty"`
+	Metadata    *map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateCalendar creates a new calendar.
+// See: https://developer.nylas.com/docs/api/#post/calendars
+func (c *Client) CreateCalendar(ctx context.Context, calendarReq CalendarRequest) (Calendar, error) {
+	if err := validateMetadata(calendarReq.Metadata); err != nil {
+		return Calendar{}, err
+	}
+
+	req, err := c.newUserRequest(ctx, http.MethodPost, "/calendars", &calendarReq)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var resp Calendar
+	return resp, c.do(req, &resp)
+}
+
+// UpdateCalendar updates a calendar with the id.
+// See: https://developer.nylas.com/docs/api/#put/calendars/id
+func (c *Client) UpdateCalendar(
+	ctx context.Context, id string, updateReq UpdateCalendarRequest,
+) (Calendar, error) {
+	if updateReq.Metadata != nil {
+		if err := validateMetadata(*updateReq.Metadata); err != nil {
+			return Calendar{}, err
+		}
+	}
+
+	req, err := c.newUserRequest(ctx, http.MethodPut, "/calendars/"+id, &updateReq)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var resp Calendar
+	return resp, c.do(req, &resp)
+}
+
+// DeleteCalendar deletes the calendar with the id.
+// See: https://developer.nylas.com/docs/api/#delete/calendars/id
+func (c *Client) DeleteCalendar(ctx context.Context, id string) error {
+	req, err := c.newUserRequest(ctx, http.MethodDelete, "/calendars/"+id, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+// TimeSlot status constants.
+const (
+	TimeSlotStatusBusy = "busy"
+	TimeSlotStatusFree = "free"
+)
+
+// TimeSlot is a span of time which is either busy or free.
+type TimeSlot struct {
+	Object    string
+	Status    string
+	StartTime time.Time
+	EndTime   time.Time
+	// Emails of the participants free during the slot, only set for slots
+	// returned by the Availability method.
+	Emails []string
+}
+
+type timeSlotJSON struct {
+	Object    string   `json:"object"`
+	Status    string   `json:"status"`
+	StartTime int64    `json:"start_time"`
+	EndTime   int64    `json:"end_time"`
+	Emails    []string `json:"emails,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s TimeSlot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timeSlotJSON{
+		Object:    s.Object,
+		Status:    s.Status,
+		StartTime: s.StartTime.Unix(),
+		EndTime:   s.EndTime.Unix(),
+		Emails:    s.Emails,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *TimeSlot) UnmarshalJSON(b []byte) error {
+	var ts timeSlotJSON
+	if err := json.Unmarshal(b, &ts); err != nil {
+		return err
+	}
+	*s = TimeSlot{
+		Object:    ts.Object,
+		Status:    ts.Status,
+		StartTime: time.Unix(ts.StartTime, 0),
+		EndTime:   time.Unix(ts.EndTime, 0),
+		Emails:    ts.Emails,
+	}
+	return nil
+}
+
+// FreeBusy contains the busy time slots of a single email address.
+type FreeBusy struct {
+	Object    string     `json:"object"`
+	Email     string     `json:"email"`
+	TimeSlots []TimeSlot `json:"time_slots"`
+}
+
+// FreeBusy returns the busy time slots between start and end for each of the
+// email addresses.
+// See: https://docs.nylas.com/reference#calendars-free-busy
+func (c *Client) FreeBusy(
+	ctx context.Context, emails []string, start, end time.Time,
+) ([]FreeBusy, error) {
+	req, err := c.newUserRequest(ctx, http.MethodPost, "/calendars/free-busy", map[string]interface{}{
+		"start_time": strconv.FormatInt(start.Unix(), 10),
+		"end_time":   strconv.FormatInt(end.Unix(), 10),
+		"emails":     emails,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []FreeBusy
+	return resp, c.do(req, &resp)
+}
+
+// OpenHours restricts the times a set of participants are available, e.g. to
+// their working hours.
+type OpenHours struct {
+	// Emails of the participants the open hours apply to.
+	Emails []string
+	// Days of the week the open hours apply to.
+	Days []time.Weekday
+	// Timezone the Start and End times are in.
+	Timezone *time.Location
+	// Start and End of the open hours as "15:04" formatted times.
+	Start string
+	End   string
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (h OpenHours) MarshalJSON() ([]byte, error) {
+	timezone := "UTC"
+	if h.Timezone != nil {
+		timezone = h.Timezone.String()
+	}
+	return json.Marshal(map[string]interface{}{
+		"emails":      h.Emails,
+		"days":        h.Days,
+		"timezone":    timezone,
+		"start":       h.Start,
+		"end":         h.End,
+		"object_type": "open_hours",
+	})
+}
+
+// AvailabilityRequest contains the request parameters required to find the
+// time slots when participants are available to meet.
+// See: https://docs.nylas.com/reference#availability
+type AvailabilityRequest struct {
+	// Emails of the participants which must be available.
+	Emails []string
+	// Start and End of the window to search for available time slots.
+	Start time.Time
+	End   time.Time
+	// Duration of the meeting, which is rounded down to whole minutes.
+	Duration time.Duration
+	// Interval between the start of each returned time slot, defaults to
+	// Duration when zero.
+	Interval time.Duration
+	// Buffer required free around existing events.
+	Buffer time.Duration
+	// OpenHours restricts when participants are available, participants
+	// without open hours are available at any time.
+	OpenHours []OpenHours
+	// FreeBusy provides the busy time slots of participants whose calendars
+	// are not accessible to Nylas.
+	FreeBusy []FreeBusy
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r AvailabilityRequest) MarshalJSON() ([]byte, error) {
+	interval := r.Interval
+	if interval == 0 {
+		interval = r.Duration
+	}
+	openHours := r.OpenHours
+	if openHours == nil {
+		openHours = []OpenHours{}
+	}
+	freeBusy := r.FreeBusy
+	if freeBusy == nil {
+		freeBusy = []FreeBusy{}
+	}
+	return json.Marshal(map[string]interface{}{
+		"emails":           r.Emails,
+		"start_time":       r.Start.Unix(),
+		"end_time":         r.End.Unix(),
+		"duration_minutes": int(r.Duration / time.Minute),
+		"interval_minutes": int(interval / time.Minute),
+		"buffer":           int(r.Buffer / time.Minute),
+		"open_hours":       openHours,
+		"free_busy":        freeBusy,
+	})
+}
+
+// Availability contains the time slots when all participants of an
+// AvailabilityRequest are available.
+type Availability struct {
+	Object    string     `json:"object"`
+	TimeSlots []TimeSlot `json:"time_slots"`
+}
+
+// Availability returns the time slots when the participants are available.
+// See: https://docs.nylas.com/reference#availability
+func (c *Client) Availability(
+	ctx context.Context, availabilityReq AvailabilityRequest,
+) (Availability, error) {
+	req, err := c.newUserRequest(ctx, http.MethodPost, "/calendars/availability", &availabilityReq)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	var resp Availability
+	return resp, c.do(req, &resp)
+}
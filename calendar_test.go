@@ -2,10 +2,13 @@ package nylas
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -95,6 +98,268 @@ func TestCalendars(t *testing.T) {
 	}
 }
 
+func TestCreateCalendar(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := []byte(`{"name":"name","description":"description","location":"Dublin",` +
+		`"timezone":"America/New_York","metadata":{"key":"value"}}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/calendars")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(getCalendarJSON)
+	}))
+	defer ts.Close()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	_, err = client.CreateCalendar(context.Background(), CalendarRequest{
+		Name:        "name",
+		Description: "description",
+		Location:    "Dublin",
+		TimeZone:    &TimeZone{Location: loc},
+		Metadata:    map[string]string{"key": "value"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUpdateCalendar(t *testing.T) {
+	accessToken := "accessToken"
+	id := "8cid1lhd0m7x9k5wjrkpufs1a"
+	wantBody := []byte(`{"name":"new name","metadata":{}}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPut, "/calendars/"+id)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(getCalendarJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	_, err := client.UpdateCalendar(context.Background(), id, UpdateCalendarRequest{
+		Name:     String("new name"),
+		Metadata: &map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteCalendar(t *testing.T) {
+	accessToken := "accessToken"
+	id := "8cid1lhd0m7x9k5wjrkpufs1a"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodDelete, "/calendars/"+id)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	if err := client.DeleteCalendar(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTimeZoneJSON(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	tests := map[string]struct {
+		in      string
+		want    TimeZone
+		wantErr bool
+	}{
+		"zone":    {in: `"America/New_York"`, want: TimeZone{Location: loc}},
+		"null":    {in: `null`, want: TimeZone{}},
+		"number":  {in: `1`, wantErr: true},
+		"unknown": {in: `"Nowhere/Special"`, wantErr: true},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var got TimeZone
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !compareTimeZones(&got, &tt.want) {
+				t.Errorf("UnmarshalJSON: got %v; want %v", got.Location, tt.want.Location)
+			}
+
+			out, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(out) != tt.in {
+				t.Errorf("MarshalJSON: got %s; want %s", out, tt.in)
+			}
+		})
+	}
+}
+
+func TestFreeBusy(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := []byte(`{"emails":["swag@nylas.com"],"end_time":"1409598000","start_time":"1409594400"}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/calendars/free-busy")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write([]byte(`[{
+			"object": "free_busy",
+			"email": "swag@nylas.com",
+			"time_slots": [{
+				"object": "time_slot",
+				"status": "busy",
+				"start_time": 1409594400,
+				"end_time": 1409598000
+			}]
+		}]`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.FreeBusy(context.Background(), []string{"swag@nylas.com"},
+		time.Unix(1409594400, 0), time.Unix(1409598000, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []FreeBusy{{
+		Object: "free_busy",
+		Email:  "swag@nylas.com",
+		TimeSlots: []TimeSlot{{
+			Object:    "time_slot",
+			Status:    TimeSlotStatusBusy,
+			StartTime: time.Unix(1409594400, 0),
+			EndTime:   time.Unix(1409598000, 0),
+		}},
+	}}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("FreeBusy: (-got +want):\n%s", diff)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := `{"buffer":5,"duration_minutes":30,"emails":["a@example.com","b@example.com"],` +
+		`"end_time":1605830400,"free_busy":[{"object":"free_busy","email":"c@example.com",` +
+		`"time_slots":[{"object":"time_slot","status":"busy","start_time":1605794400,"end_time":1605796200}]}],` +
+		`"interval_minutes":30,"open_hours":[{"days":[1,2,3,4,5],"emails":["a@example.com"],` +
+		`"end":"17:00","object_type":"open_hours","start":"09:00","timezone":"America/New_York"}],` +
+		`"start_time":1605744000}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/calendars/availability")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(string(body), wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"object": "availability",
+			"time_slots": [{
+				"object": "time_slot",
+				"status": "free",
+				"start_time": 1605798000,
+				"end_time": 1605799800,
+				"emails": ["a@example.com", "b@example.com"]
+			}]
+		}`))
+	}))
+	defer ts.Close()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.Availability(context.Background(), AvailabilityRequest{
+		Emails:   []string{"a@example.com", "b@example.com"},
+		Start:    time.Unix(1605744000, 0),
+		End:      time.Unix(1605830400, 0),
+		Duration: 30 * time.Minute,
+		Buffer:   5 * time.Minute,
+		OpenHours: []OpenHours{{
+			Emails:   []string{"a@example.com"},
+			Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Timezone: loc,
+			Start:    "09:00",
+			End:      "17:00",
+		}},
+		FreeBusy: []FreeBusy{{
+			Object: "free_busy",
+			Email:  "c@example.com",
+			TimeSlots: []TimeSlot{{
+				Object:    "time_slot",
+				Status:    TimeSlotStatusBusy,
+				StartTime: time.Unix(1605794400, 0),
+				EndTime:   time.Unix(1605796200, 0),
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Availability{
+		Object: "availability",
+		TimeSlots: []TimeSlot{{
+			Object:    "time_slot",
+			Status:    TimeSlotStatusFree,
+			StartTime: time.Unix(1605798000, 0),
+			EndTime:   time.Unix(1605799800, 0),
+			Emails:    []string{"a@example.com", "b@example.com"},
+		}},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Availability: (-got +want):\n%s", diff)
+	}
+}
+
 func compareTimeZones(x, y *TimeZone) bool {
 	if x == nil && y == nil {
 		return true
@@ -115,3 +380,20 @@ var getCalendarJSON = []byte(`{
     "read_only": false,
 	"timezone": "America/New_York"
 }`)
+
+func TestCalendarInvalidMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	metadata := map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "value"}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	if _, err := client.CreateCalendar(context.Background(), CalendarRequest{Metadata: metadata}); err == nil {
+		t.Error("CreateCalendar: expected error")
+	}
+	_, err := client.UpdateCalendar(context.Background(), "id", UpdateCalendarRequest{Metadata: &metadata})
+	if err == nil {
+		t.Error("UpdateCalendar: expected error")
+	}
+}
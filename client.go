@@ -157,11 +157,7 @@ func (c *Client) do(req *http.Request, v interface{}) error {
 	defer resp.Body.Close() // nolint: errcheck
 
 	if resp.StatusCode >= 299 {
-		e := NewError(resp)
-		if c.errorHandler != nil {
-			return c.errorHandler(e)
-		}
-		return e
+		return c.responseError(resp)
 	}
 
 	if v != nil {
@@ -170,6 +166,16 @@ func (c *Client) do(req *http.Request, v interface{}) error {
 	return nil
 }
 
+// responseError creates an Error from an unsuccessful API response and passes
+// it through the error handler, if one is set.
+func (c *Client) responseError(resp *http.Response) error {
+	e := NewError(resp)
+	if c.errorHandler != nil {
+		return c.errorHandler(e)
+	}
+	return e
+}
+
 func appendQueryValues(req *http.Request, values url.Values) {
 	q := req.URL.Query()
 	for k, vs := range values {
@@ -0,0 +1,186 @@
+package nylas
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// attributionDateFormat is the format of message dates in reply attribution
+// lines and forwarded message headers.
+const attributionDateFormat = "Mon, Jan 2, 2006 at 3:04 PM MST"
+
+var (
+	replyPrefix   = regexp.MustCompile(`(?i)^re\s*:`)
+	forwardPrefix = regexp.MustCompile(`(?i)^fwd?\s*:`)
+	// htmlTag only matches known tags so plain text bodies which include
+	// addresses such as <alice@example.com> are not taken for HTML.
+	htmlTag = regexp.MustCompile(`(?i)<(!doctype|!--|/?(html|head|body|div|p|br|span|a|b|i|u|em|strong|` +
+		`font|img|hr|h[1-6]|pre|table|tr|td|th|ul|ol|li|blockquote|style|meta)[\s/>])`)
+)
+
+// NewReplyDraft creates a draft replying to msg.
+//
+// The reply is addressed to the message ReplyTo participants, falling back to
+// From, and when replyAll is set also to the other To and CC participants.
+// Recipients are de-duplicated and selfEmail, the address of the account
+// replying, is removed; replying to a message sent by selfEmail addresses the
+// original recipients instead.
+//
+// The subject is prefixed with "Re:" and the body quotes the original message
+// after an attribution line. The body is always HTML, as the API expects,
+// with plain text bodies escaped and their line breaks kept.
+func NewReplyDraft(msg Message, replyAll bool, selfEmail string) DraftRequest {
+	to := msg.ReplyTo
+	if len(to) == 0 {
+		to = msg.From
+	}
+	if onlyAddress(to, selfEmail) {
+		to = msg.To
+	}
+
+	seen := map[string]bool{strings.ToLower(selfEmail): true}
+	draft := DraftRequest{
+		Subject:          prefixSubject(msg.Subject, "Re:", replyPrefix),
+		To:               uniqueParticipants(seen, to),
+		ReplyToMessageID: msg.ID,
+		Body:             quoteBody(msg),
+	}
+	if replyAll {
+		draft.To = append(draft.To, uniqueParticipants(seen, msg.To)...)
+		draft.CC = uniqueParticipants(seen, msg.CC)
+	}
+	return draft
+}
+
+// NewForwardDraft creates a draft forwarding msg, without any recipients.
+//
+// The subject is prefixed with "Fwd:", the body includes the original message
+// as HTML after a forwarded message header and the original attachments are
+// carried over by their file ID.
+func NewForwardDraft(msg Message) DraftRequest {
+	draft := DraftRequest{
+		Subject: prefixSubject(msg.Subject, "Fwd:", forwardPrefix),
+		Body:    forwardBody(msg),
+	}
+	for _, f := range msg.Files {
+		if f.ID != "" {
+			draft.FileIDs = append(draft.FileIDs, f.ID)
+		}
+	}
+	return draft
+}
+
+// prefixSubject prefixes subject with prefix unless re already matches it.
+func prefixSubject(subject, prefix string, re *regexp.Regexp) string {
+	subject = strings.TrimSpace(subject)
+	if re.MatchString(subject) {
+		return subject
+	}
+	if subject == "" {
+		return prefix
+	}
+	return prefix + " " + subject
+}
+
+// onlyAddress reports whether all of ps, of which there is at least one, have
+// the given email address.
+func onlyAddress(ps []Participant, email string) bool {
+	if len(ps) == 0 || email == "" {
+		return false
+	}
+	for _, p := range ps {
+		if !strings.EqualFold(p.Email, email) {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueParticipants returns the participants in ps whose email addresses
+// have not been seen, marking them as seen.
+func uniqueParticipants(seen map[string]bool, ps []Participant) []Participant {
+	var unique []Participant
+	for _, p := range ps {
+		email := strings.ToLower(p.Email)
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		unique = append(unique, p)
+	}
+	return unique
+}
+
+// formatParticipant formats p as a "Name <email>" address.
+func formatParticipant(p Participant) string {
+	if p.Name == "" {
+		return p.Email
+	}
+	return p.Name + " <" + p.Email + ">"
+}
+
+func formatParticipants(ps []Participant) string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = formatParticipant(p)
+	}
+	return strings.Join(s, ", ")
+}
+
+func formatMessageDate(msg Message) string {
+	if msg.Date == 0 {
+		return ""
+	}
+	return time.Unix(msg.Date, 0).UTC().Format(attributionDateFormat)
+}
+
+// isHTML reports whether body appears to contain HTML markup.
+func isHTML(body string) bool {
+	return htmlTag.MatchString(body)
+}
+
+// quoteBody quotes the body of msg after an attribution line.
+func quoteBody(msg Message) string {
+	attribution := "wrote:"
+	if len(msg.From) > 0 {
+		attribution = formatParticipant(msg.From[0]) + " " + attribution
+	}
+	if date := formatMessageDate(msg); date != "" {
+		attribution = "On " + date + ", " + attribution
+	}
+
+	body := msg.Body
+	if !isHTML(body) {
+		body = textToHTML(body)
+	}
+	return "<br><br><div class=\"nylas_quote\"><div>" + html.EscapeString(attribution) +
+		"</div><blockquote style=\"margin:0 0 0 .8ex;border-left:1px #ccc solid;padding-left:1ex\">" +
+		body + "</blockquote></div>"
+}
+
+// forwardBody includes the body of msg after a forwarded message header.
+func forwardBody(msg Message) string {
+	header := [][2]string{
+		{"From", formatParticipants(msg.From)},
+		{"Date", formatMessageDate(msg)},
+		{"Subject", msg.Subject},
+		{"To", formatParticipants(msg.To)},
+		{"Cc", formatParticipants(msg.CC)},
+	}
+
+	body := msg.Body
+	if !isHTML(body) {
+		body = textToHTML(body)
+	}
+	var b strings.Builder
+	b.WriteString("<br><br><div class=\"nylas_forward\">---------- Forwarded message ---------")
+	for _, h := range header {
+		if h[1] != "" {
+			b.WriteString("<br>" + h[0] + ": " + html.EscapeString(h[1]))
+		}
+	}
+	b.WriteString("<br><br>" + body + "</div>")
+	return b.String()
+}
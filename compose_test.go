@@ -0,0 +1,156 @@
+package nylas
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewReplyDraft(t *testing.T) {
+	alice := Participant{Name: "Alice", Email: "alice@example.com"}
+	bob := Participant{Name: "Bob", Email: "bob@example.com"}
+	carol := Participant{Email: "carol@example.com"}
+	self := Participant{Name: "Me", Email: "me@example.com"}
+	msg := Message{
+		ID:      "msgid",
+		Subject: "Lunch",
+		Date:    1583157600,
+		From:    []Participant{alice},
+		To:      []Participant{self, bob},
+		CC:      []Participant{{Email: "ALICE@example.com"}, carol},
+		Body:    "<p>Tacos?</p>",
+	}
+	body := `<br><br><div class="nylas_quote"><div>On Mon, Mar 2, 2020 at 2:00 PM UTC, ` +
+		`Alice &lt;alice@example.com&gt; wrote:</div><blockquote style="margin:0 0 0 .8ex;` +
+		`border-left:1px #ccc solid;padding-left:1ex"><p>Tacos?</p></blockquote></div>`
+
+	tests := map[string]struct {
+		msg      func(Message) Message
+		replyAll bool
+		want     DraftRequest
+	}{
+		"reply": {
+			want: DraftRequest{
+				Subject:          "Re: Lunch",
+				To:               []Participant{alice},
+				ReplyToMessageID: "msgid",
+				Body:             body,
+			},
+		},
+		"reply all": {
+			replyAll: true,
+			want: DraftRequest{
+				Subject:          "Re: Lunch",
+				To:               []Participant{alice, bob},
+				CC:               []Participant{carol},
+				ReplyToMessageID: "msgid",
+				Body:             body,
+			},
+		},
+		"reply to": {
+			// ReplyTo replaces From, so Alice is only included as a CC.
+			msg: func(m Message) Message {
+				m.Subject = "RE: Lunch"
+				m.ReplyTo = []Participant{carol}
+				return m
+			},
+			replyAll: true,
+			want: DraftRequest{
+				Subject:          "RE: Lunch",
+				To:               []Participant{carol, bob},
+				CC:               []Participant{{Email: "ALICE@example.com"}},
+				ReplyToMessageID: "msgid",
+				Body:             body,
+			},
+		},
+		"own message": {
+			msg: func(m Message) Message {
+				m.From = []Participant{{Email: "ME@example.com"}}
+				m.To = []Participant{bob}
+				m.CC = nil
+				m.Date = 0
+				m.Body = "See you there\n\n> Tacos? <bob@example.com>\n"
+				return m
+			},
+			want: DraftRequest{
+				Subject:          "Re: Lunch",
+				To:               []Participant{bob},
+				ReplyToMessageID: "msgid",
+				Body: `<br><br><div class="nylas_quote"><div>ME@example.com wrote:</div>` +
+					`<blockquote style="margin:0 0 0 .8ex;border-left:1px #ccc solid;padding-left:1ex">` +
+					`<div>See you there<br><br>&gt; Tacos? &lt;bob@example.com&gt;</div></blockquote></div>`,
+			},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			m := msg
+			if tt.msg != nil {
+				m = tt.msg(m)
+			}
+			got := NewReplyDraft(m, tt.replyAll, "me@example.com")
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("NewReplyDraft: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewForwardDraft(t *testing.T) {
+	msg := Message{
+		Subject: "Report",
+		Date:    1583157600,
+		From:    []Participant{{Name: "Alice", Email: "alice@example.com"}},
+		To:      []Participant{{Email: "bob@example.com"}},
+		Body:    "See attached.",
+		Files:   []File{{ID: "file1"}, {ID: "file2"}},
+	}
+
+	got := NewForwardDraft(msg)
+	want := DraftRequest{
+		Subject: "Fwd: Report",
+		Body: `<br><br><div class="nylas_forward">---------- Forwarded message ---------` +
+			"<br>From: Alice &lt;alice@example.com&gt;" +
+			"<br>Date: Mon, Mar 2, 2020 at 2:00 PM UTC" +
+			"<br>Subject: Report" +
+			"<br>To: bob@example.com" +
+			"<br><br><div>See attached.</div></div>",
+		FileIDs: []string{"file1", "file2"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("NewForwardDraft: (-got +want):\n%s", diff)
+	}
+
+	msg.Subject = "FW: Report"
+	msg.Body = "<b>See attached.</b>"
+	got = NewForwardDraft(msg)
+	want.Subject = "FW: Report"
+	want.Body = `<br><br><div class="nylas_forward">---------- Forwarded message ---------` +
+		"<br>From: Alice &lt;alice@example.com&gt;" +
+		"<br>Date: Mon, Mar 2, 2020 at 2:00 PM UTC" +
+		"<br>Subject: FW: Report" +
+		"<br>To: bob@example.com" +
+		"<br><br><b>See attached.</b></div>"
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("NewForwardDraft: (-got +want):\n%s", diff)
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	tests := map[string]bool{
+		"<p>Hello</p>":                        true,
+		"Hi<br/>there":                        true,
+		"<!DOCTYPE html><html></html>":        true,
+		`<A HREF="https://example.com">x</A>`: true,
+		"Plain text":                          false,
+		"From: Alice <alice@example.com>":     false,
+		"a < b and c > d":                     false,
+		"<https://example.com/path>":          false,
+	}
+	for body, want := range tests {
+		if got := isHTML(body); got != want {
+			t.Errorf("isHTML(%q) = %t, want %t", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package nylas
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Conferencing provider constants.
+const (
+	ConferencingProviderGoogleMeet     = "Google Meet"
+	ConferencingProviderZoom           = "Zoom Meeting"
+	ConferencingProviderMicrosoftTeams = "Microsoft Teams"
+	ConferencingProviderWebEx          = "WebEx"
+	ConferencingProviderGoToMeeting    = "GoToMeeting"
+)
+
+// Conferencing contains the details of an event's video conference.
+type Conferencing struct {
+	// One of the ConferencingProvider constants.
+	Provider string              `json:"provider"`
+	Details  ConferencingDetails `json:"details"`
+}
+
+// ConferencingDetails contains how to join a video conference.
+type ConferencingDetails struct {
+	URL         string   `json:"url,omitempty"`
+	MeetingCode string   `json:"meeting_code,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	PIN         string   `json:"pin,omitempty"`
+	Phone       []string `json:"phone,omitempty"`
+}
+
+// conferencingPattern detects a provider's join links, the first submatch
+// if any is the meeting code.
+type conferencingPattern struct {
+	provider string
+	re       *regexp.Regexp
+}
+
+// urlChars matches the characters of a URL up to whitespace, quotes or angle
+// brackets, which delimit links in both plain text and HTML.
+const urlChars = `[^\s"'<>]*`
+
+var conferencingPatterns = []conferencingPattern{
+	{
+		ConferencingProviderZoom,
+		regexp.MustCompile(`https://(?:[\w-]+\.)*zoom\.us/(?:j|w|my|s)/([\w.-]+)` + urlChars),
+	},
+	{
+		ConferencingProviderGoogleMeet,
+		regexp.MustCompile(`https://meet\.google\.com/([a-z]{3}-[a-z]{4}-[a-z]{3})` + urlChars),
+	},
+	{
+		ConferencingProviderMicrosoftTeams,
+		regexp.MustCompile(`https://teams\.(?:microsoft|live)\.com/(?:l/meetup-join|meet)/` + urlChars),
+	},
+	{
+		ConferencingProviderWebEx,
+		regexp.MustCompile(`https://[\w-]+\.webex\.com/` + urlChars),
+	},
+	{
+		ConferencingProviderGoToMeeting,
+		regexp.MustCompile(`https://(?:(?:global|app)\.gotomeeting\.com/join|meet\.goto\.com)/(\d+)` + urlChars),
+	},
+}
+
+// ExtractConferencing returns the conferencing details of the first
+// recognized join link in text, which may be plain text or HTML, or nil if
+// there is none.
+func ExtractConferencing(text string) *Conferencing {
+	var (
+		found *Conferencing
+		at    = -1
+	)
+	for _, p := range conferencingPatterns {
+		m := p.re.FindStringSubmatchIndex(text)
+		if m == nil || (at >= 0 && m[0] >= at) {
+			continue
+		}
+		at = m[0]
+
+		link := strings.TrimRight(text[m[0]:m[1]], ".,;:!?)]}")
+		link = strings.Replace(link, "&amp;", "&", -1)
+		found = &Conferencing{
+			Provider: p.provider,
+			Details:  ConferencingDetails{URL: link},
+		}
+		if len(m) > 2 && m[2] >= 0 {
+			found.Details.MeetingCode = text[m[2]:m[3]]
+		}
+		if u, err := url.Parse(link); err == nil {
+			found.Details.Password = u.Query().Get("pwd")
+		}
+	}
+	return found
+}
+
+// FindConferencing returns the event's Conferencing if it has a join URL,
+// otherwise the first recognized join link in its Location and then its
+// Description, or nil if there is none.
+func (e Event) FindConferencing() *Conferencing {
+	if e.Conferencing != nil && e.Conferencing.Details.URL != "" {
+		return e.Conferencing
+	}
+	if c := ExtractConferencing(e.Location); c != nil {
+		return c
+	}
+	if c := ExtractConferencing(e.Description); c != nil {
+		return c
+	}
+	return e.Conferencing
+}
@@ -0,0 +1,141 @@
+package nylas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractConferencing(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want *Conferencing
+	}{
+		"none": {"Room 1, West Building", nil},
+		"zoom": {
+			"Join Zoom Meeting\nhttps://us02web.zoom.us/j/85551234567?pwd=aBcD123.\nMeeting ID: 855 5123 4567",
+			&Conferencing{
+				Provider: ConferencingProviderZoom,
+				Details: ConferencingDetails{
+					URL:         "https://us02web.zoom.us/j/85551234567?pwd=aBcD123",
+					MeetingCode: "85551234567",
+					Password:    "aBcD123",
+				},
+			},
+		},
+		"meet in html": {
+			`<p>Join with <a href="https://meet.google.com/abc-defg-hij?authuser=0&amp;hs=1">Google Meet</a></p>`,
+			&Conferencing{
+				Provider: ConferencingProviderGoogleMeet,
+				Details: ConferencingDetails{
+					URL:         "https://meet.google.com/abc-defg-hij?authuser=0&hs=1",
+					MeetingCode: "abc-defg-hij",
+				},
+			},
+		},
+		"teams": {
+			"Click here to join (https://teams.microsoft.com/l/meetup-join/19%3ameeting_N2Q%40thread.v2/0?context=%7b%7d)",
+			&Conferencing{
+				Provider: ConferencingProviderMicrosoftTeams,
+				Details: ConferencingDetails{
+					URL: "https://teams.microsoft.com/l/meetup-join/19%3ameeting_N2Q%40thread.v2/0?context=%7b%7d",
+				},
+			},
+		},
+		"webex": {
+			"https://acme.webex.com/acme/j.php?MTID=m1234",
+			&Conferencing{
+				Provider: ConferencingProviderWebEx,
+				Details:  ConferencingDetails{URL: "https://acme.webex.com/acme/j.php?MTID=m1234"},
+			},
+		},
+		"gotomeeting": {
+			"https://global.gotomeeting.com/join/123456789",
+			&Conferencing{
+				Provider: ConferencingProviderGoToMeeting,
+				Details: ConferencingDetails{
+					URL:         "https://global.gotomeeting.com/join/123456789",
+					MeetingCode: "123456789",
+				},
+			},
+		},
+		"first link wins": {
+			"Backup: https://meet.google.com/abc-defg-hij or https://zoom.us/j/123",
+			&Conferencing{
+				Provider: ConferencingProviderGoogleMeet,
+				Details: ConferencingDetails{
+					URL:         "https://meet.google.com/abc-defg-hij",
+					MeetingCode: "abc-defg-hij",
+				},
+			},
+		},
+		"lookalike domain": {"https://zoom.us.example.com/j/123", nil},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if diff := cmp.Diff(ExtractConferencing(tt.text), tt.want); diff != "" {
+				t.Errorf("ExtractConferencing: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEventFindConferencing(t *testing.T) {
+	typed := &Conferencing{
+		Provider: ConferencingProviderZoom,
+		Details:  ConferencingDetails{URL: "https://zoom.us/j/1", Phone: []string{"+1 555 0100"}},
+	}
+	noURL := &Conferencing{Provider: ConferencingProviderWebEx, Details: ConferencingDetails{PIN: "1234"}}
+	meet := "https://meet.google.com/abc-defg-hij"
+
+	tests := map[string]struct {
+		event Event
+		want  string
+	}{
+		"typed":          {Event{Conferencing: typed, Location: meet}, "https://zoom.us/j/1"},
+		"location":       {Event{Location: meet, Description: "https://zoom.us/j/2"}, meet},
+		"description":    {Event{Location: "Room 1", Description: "Join: https://zoom.us/j/2"}, "https://zoom.us/j/2"},
+		"typed fallback": {Event{Conferencing: noURL, Location: "Room 1"}, ""},
+		"none":           {Event{Location: "Room 1"}, ""},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got := tt.event.FindConferencing()
+			if tt.want == "" {
+				if got != tt.event.Conferencing {
+					t.Errorf("FindConferencing: got %+v; want %+v", got, tt.event.Conferencing)
+				}
+				return
+			}
+			if got == nil || got.Details.URL != tt.want {
+				t.Errorf("FindConferencing: got %+v; want URL %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventRequestConferencingJSON(t *testing.T) {
+	got, err := json.Marshal(EventRequest{
+		CalendarID: "calendar",
+		Conferencing: &Conferencing{
+			Provider: ConferencingProviderZoom,
+			Details: ConferencingDetails{
+				URL:         "https://zoom.us/j/1",
+				MeetingCode: "1",
+				Phone:       []string{"+1 555 0100"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"calendar_id":"calendar","conferencing":{"provider":"Zoom Meeting",` +
+		`"details":{"url":"https://zoom.us/j/1","meeting_code":"1","phone":["+1 555 0100"]}}}`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("EventRequest: (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,63 @@
+package nylas
+
+import (
+	"context"
+	"net/http"
+)
+
+// Contact represents a contact in the Nylas system.
+// See: https://docs.nylas.com/reference#contacts
+type Contact struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	AccountID string `json:"account_id"`
+
+	GivenName      string `json:"given_name"`
+	MiddleName     string `json:"middle_name"`
+	Surname        string `json:"surname"`
+	Suffix         string `json:"suffix"`
+	Nickname       string `json:"nickname"`
+	Birthday       string `json:"birthday"`
+	CompanyName    string `json:"company_name"`
+	JobTitle       string `json:"job_title"`
+	ManagerName    string `json:"manager_name"`
+	OfficeLocation string `json:"office_location"`
+	Notes          string `json:"notes"`
+	PictureURL     string `json:"picture_url"`
+	// Source is either "address_book" or "inbox".
+	Source string `json:"source"`
+
+	Emails       []ContactEmail       `json:"emails"`
+	PhoneNumbers []ContactPhoneNumber `json:"phone_numbers"`
+	WebPages     []ContactWebPage     `json:"web_pages"`
+}
+
+// ContactEmail is an email address of a Contact.
+type ContactEmail struct {
+	Type  string `json:"type"`
+	Email string `json:"email"`
+}
+
+// ContactPhoneNumber is a phone number of a Contact.
+type ContactPhoneNumber struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+// ContactWebPage is a web page of a Contact.
+type ContactWebPage struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Contact returns a contact by id.
+// See: https://docs.nylas.com/reference#contactsid
+func (c *Client) Contact(ctx context.Context, id string) (Contact, error) {
+	req, err := c.newUserRequest(ctx, http.MethodGet, "/contacts/"+id, nil)
+	if err != nil {
+		return Contact{}, err
+	}
+
+	var resp Contact
+	return resp, c.do(req, &resp)
+}
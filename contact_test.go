@@ -0,0 +1,68 @@
+package nylas
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContact(t *testing.T) {
+	accessToken := "accessToken"
+	id := "5x6b54whvcz1j22ggiyorhk9v"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/contacts/"+id)
+
+		_, _ = w.Write(getContactJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.Contact(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Contact{
+		ID:          "5x6b54whvcz1j22ggiyorhk9v",
+		Object:      "contact",
+		AccountID:   "43jf3n4e***",
+		GivenName:   "Dorothy",
+		Surname:     "Vaughan",
+		CompanyName: "NASA",
+		JobTitle:    "Mathematician",
+		Source:      "address_book",
+		Emails: []ContactEmail{
+			{Type: "work", Email: "dorothy@spacetech.com"},
+		},
+		PhoneNumbers: []ContactPhoneNumber{
+			{Type: "business", Number: "555-555-5555"},
+		},
+		WebPages: []ContactWebPage{},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Contact: (-got +want):\n%s", diff)
+	}
+}
+
+var getContactJSON = []byte(`{
+    "id": "5x6b54whvcz1j22ggiyorhk9v",
+    "object": "contact",
+    "account_id": "43jf3n4e***",
+    "given_name": "Dorothy",
+    "surname": "Vaughan",
+    "company_name": "NASA",
+    "job_title": "Mathematician",
+    "source": "address_book",
+    "emails": [
+        {"type": "work", "email": "dorothy@spacetech.com"}
+    ],
+    "phone_numbers": [
+        {"type": "business", "number": "555-555-5555"}
+    ],
+    "web_pages": []
+}`)
@@ -0,0 +1,286 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// defaultDraftConflictRetries is the number of times a draft request is
+// retried after a version conflict when no options are given.
+const defaultDraftConflictRetries = 3
+
+// DraftConflictError is returned by the methods handling draft version
+// conflicts, such as UpdateDraftMerged, when a request made with a version
+// which is not the latest version of the draft could not be retried.
+type DraftConflictError struct {
+	DraftID string
+	// Version the request was made with.
+	Version int
+	// Current is the latest draft.
+	Current *Draft
+	// Fields of the UpdateDraftRequest which were also changed in the
+	// latest draft, only set by UpdateDraftMerged.
+	Fields []string
+	// Err is the error returned by the API.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DraftConflictError) Error() string {
+	s := fmt.Sprintf("draft %s version %d conflict", e.DraftID, e.Version)
+	if len(e.Fields) > 0 {
+		s += " on " + strings.Join(e.Fields, ", ")
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the API.
+func (e *DraftConflictError) Unwrap() error {
+	return e.Err
+}
+
+// draftConflict wraps err in a DraftConflictError if it is a version
+// conflict, which the API returns as a 409 Conflict.
+func draftConflict(id string, version int, err error) error {
+	if !hasStatusCode(err, http.StatusConflict) {
+		return err
+	}
+	return &DraftConflictError{DraftID: id, Version: version, Err: err}
+}
+
+// DraftConflictOptions provides optional parameters to the methods handling
+// draft version conflicts.
+type DraftConflictOptions struct {
+	// MaxRetries is the number of times to retry after a conflict, defaults
+	// to 3.
+	MaxRetries int
+	// Surface returns a DraftConflictError with the latest draft on the
+	// first conflict instead of retrying.
+	Surface bool
+}
+
+func (o *DraftConflictOptions) retries() int {
+	if o == nil || o.MaxRetries <= 0 {
+		return defaultDraftConflictRetries
+	}
+	return o.MaxRetries
+}
+
+func (o *DraftConflictOptions) surface() bool {
+	return o != nil && o.Surface
+}
+
+// UpdateDraftMerged updates a draft previously fetched as base, merging the
+// update into the latest version of the draft if it has changed since.
+//
+// On a version conflict the latest draft is fetched and the update retried
+// against it, unless a field set in updateReq was also changed in the latest
+// draft to a different value, in which case a *DraftConflictError listing the
+// fields is returned. The updateReq Version is ignored in favour of the
+// version of base.
+func (c *Client) UpdateDraftMerged(
+	ctx context.Context, base Draft, updateReq UpdateDraftRequest, opts *DraftConflictOptions,
+) (Draft, error) {
+	for attempt := 0; ; attempt++ {
+		updateReq.Version = base.Version
+		draft, err := c.UpdateDraft(ctx, base.ID, updateReq)
+		err = draftConflict(base.ID, base.Version, err)
+		var conflict *DraftConflictError
+		if !errors.As(err, &conflict) {
+			return draft, err
+		}
+
+		current, err := c.Draft(ctx, base.ID)
+		if err != nil {
+			return Draft{}, err
+		}
+		conflict.Current = &current
+		conflict.Fields = conflictingDraftFields(base, current, updateReq)
+		if opts.surface() || len(conflict.Fields) > 0 || attempt >= opts.retries() {
+			return Draft{}, conflict
+		}
+		base = current
+	}
+}
+
+// DeleteDraftLatest deletes a draft, retrying with the latest version of the
+// draft on a version conflict. A *DraftConflictError is returned if the
+// retries are exhausted or DraftConflictOptions.Surface is set.
+func (c *Client) DeleteDraftLatest(
+	ctx context.Context, id string, version int, opts *DraftConflictOptions,
+) error {
+	return c.retryDraftConflict(ctx, id, version, opts, func(version int) error {
+		return c.DeleteDraft(ctx, id, version)
+	})
+}
+
+// SendDraftLatest sends a draft, retrying with the latest version of the
+// draft on a version conflict. The message sent includes any changes made
+// to the draft since version, use DraftConflictOptions.Surface to return a
+// *DraftConflictError instead.
+func (c *Client) SendDraftLatest(
+	ctx context.Context, id string, version int, opts *DraftConflictOptions,
+) (Message, error) {
+	var msg Message
+	err := c.retryDraftConflict(ctx, id, version, opts, func(version int) error {
+		var err error
+		msg, err = c.SendDraft(ctx, id, version)
+		return err
+	})
+	return msg, err
+}
+
+// retryDraftConflict calls fn with the latest version of the draft until it
+// does not return a version conflict or the retries are exhausted.
+func (c *Client) retryDraftConflict(
+	ctx context.Context, id string, version int, opts *DraftConflictOptions,
+	fn func(version int) error,
+) error {
+	for attempt := 0; ; attempt++ {
+		err := draftConflict(id, version, fn(version))
+		var conflict *DraftConflictError
+		if !errors.As(err, &conflict) {
+			return err
+		}
+
+		current, err := c.Draft(ctx, id)
+		if err != nil {
+			return err
+		}
+		conflict.Current = &current
+		if opts.surface() || attempt >= opts.retries() {
+			return conflict
+		}
+		version = current.Version
+	}
+}
+
+// draftField is a field of a draft which can be updated.
+type draftField struct {
+	name string
+	// update returns the value set in the request, ok is false if the field
+	// is not being updated.
+	update func(UpdateDraftRequest) (v interface{}, ok bool)
+	draft  func(Draft) interface{}
+	// equal compares values of the field, defaults to reflect.DeepEqual.
+	equal func(a, b interface{}) bool
+}
+
+var draftFields = []draftField{
+	{
+		name: "subject",
+		update: func(r UpdateDraftRequest) (interface{}, bool) {
+			if r.Subject == nil {
+				return nil, false
+			}
+			return *r.Subject, true
+		},
+		draft: func(d Draft) interface{} { return d.Subject },
+	},
+	participantsDraftField("from",
+		func(r UpdateDraftRequest) *[]Participant { return r.From },
+		func(d Draft) []Participant { return d.From }),
+	participantsDraftField("to",
+		func(r UpdateDraftRequest) *[]Participant { return r.To },
+		func(d Draft) []Participant { return d.To }),
+	participantsDraftField("cc",
+		func(r UpdateDraftRequest) *[]Participant { return r.CC },
+		func(d Draft) []Participant { return d.CC }),
+	participantsDraftField("bcc",
+		func(r UpdateDraftRequest) *[]Participant { return r.BCC },
+		func(d Draft) []Participant { return d.BCC }),
+	participantsDraftField("reply_to",
+		func(r UpdateDraftRequest) *[]Participant { return r.ReplyTo },
+		func(d Draft) []Participant { return d.ReplyTo }),
+	{
+		name: "reply_to_message_id",
+		update: func(r UpdateDraftRequest) (interface{}, bool) {
+			if r.ReplyToMessageID == nil {
+				return nil, false
+			}
+			return *r.ReplyToMessageID, true
+		},
+		draft: func(d Draft) interface{} { return d.ReplyToMessageID },
+	},
+	{
+		name: "body",
+		update: func(r UpdateDraftRequest) (interface{}, bool) {
+			if r.Body == nil {
+				return nil, false
+			}
+			return *r.Body, true
+		},
+		draft: func(d Draft) interface{} { return d.Body },
+	},
+	{
+		name: "file_ids",
+		update: func(r UpdateDraftRequest) (interface{}, bool) {
+			if r.FileIDs == nil {
+				return nil, false
+			}
+			return append([]string{}, *r.FileIDs...), true
+		},
+		draft: func(d Draft) interface{} {
+			ids := []string{}
+			for _, f := range d.Files {
+				ids = append(ids, f.ID)
+			}
+			return ids
+		},
+		// The API may return the files in a different order.
+		equal: func(a, b interface{}) bool {
+			return reflect.DeepEqual(stringSet(a.([]string)), stringSet(b.([]string)))
+		},
+	},
+}
+
+func participantsDraftField(
+	name string, update func(UpdateDraftRequest) *[]Participant, draft func(Draft) []Participant,
+) draftField {
+	return draftField{
+		name: name,
+		update: func(r UpdateDraftRequest) (interface{}, bool) {
+			ps := update(r)
+			if ps == nil {
+				return nil, false
+			}
+			return append([]Participant{}, *ps...), true
+		},
+		draft: func(d Draft) interface{} {
+			return append([]Participant{}, draft(d)...)
+		},
+	}
+}
+
+// conflictingDraftFields returns the names of the fields set in updateReq
+// which were changed between base and current to a different value.
+func conflictingDraftFields(base, current Draft, updateReq UpdateDraftRequest) []string {
+	var fields []string
+	for _, f := range draftFields {
+		v, ok := f.update(updateReq)
+		if !ok {
+			continue
+		}
+		equal := f.equal
+		if equal == nil {
+			equal = reflect.DeepEqual
+		}
+		now := f.draft(current)
+		if !equal(f.draft(base), now) && !equal(v, now) {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
+
+func stringSet(s []string) map[string]bool {
+	set := make(map[string]bool, len(s))
+	for _, v := range s {
+		set[v] = true
+	}
+	return set
+}
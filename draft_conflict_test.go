@@ -0,0 +1,310 @@
+package nylas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// conflictDraftServer is a fake drafts API which rejects requests with a stale
+// version.
+type conflictDraftServer struct {
+	t     *testing.T
+	mu    sync.Mutex
+	draft Draft
+	sent  bool
+}
+
+func (s *conflictDraftServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conflict := func(version int) bool {
+		if version == s.draft.Version {
+			return false
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"Draft has been updated","type":"invalid_request_error"}`))
+		return true
+	}
+
+	switch {
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(s.draft)
+	case r.Method == http.MethodPut:
+		var req struct {
+			Subject *string        `json:"subject"`
+			To      *[]Participant `json:"to"`
+			Body    *string        `json:"body"`
+			Version int            `json:"version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("failed to decode request body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if conflict(req.Version) {
+			return
+		}
+		if req.Subject != nil {
+			s.draft.Subject = *req.Subject
+		}
+		if req.To != nil {
+			s.draft.To = *req.To
+		}
+		if req.Body != nil {
+			s.draft.Body = *req.Body
+		}
+		s.draft.Version++
+		_ = json.NewEncoder(w).Encode(s.draft)
+	case r.Method == http.MethodDelete:
+		var req struct {
+			Version int `json:"version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("failed to decode request body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if conflict(req.Version) {
+			return
+		}
+		s.draft = Draft{}
+	case r.Method == http.MethodPost && r.URL.Path == "/send":
+		var req struct {
+			Version int `json:"version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("failed to decode request body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if conflict(req.Version) {
+			return
+		}
+		s.sent = true
+		_ = json.NewEncoder(w).Encode(s.draft.Message)
+	default:
+		s.t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		http.Error(w, "unexpected request", http.StatusNotFound)
+	}
+}
+
+// edit changes the draft as another editor would.
+func (s *conflictDraftServer) edit(fn func(d *Draft)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.draft)
+	s.draft.Version++
+}
+
+func newConflictDraftServer(t *testing.T) (*conflictDraftServer, *Client, func()) {
+	s := &conflictDraftServer{t: t, draft: Draft{
+		Message: Message{
+			ID:      "draft1",
+			Subject: "Subject",
+			To:      []Participant{{Email: "to@example.org"}},
+			Body:    "body",
+		},
+	}}
+	ts := httptest.NewServer(s)
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	return s, client, ts.Close
+}
+
+func TestUpdateDraftMerged(t *testing.T) {
+	t.Run("no conflict", func(t *testing.T) {
+		s, client, done := newConflictDraftServer(t)
+		defer done()
+
+		got, err := client.UpdateDraftMerged(context.Background(), s.draft, UpdateDraftRequest{
+			Subject: String("New subject"),
+		}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Subject != "New subject" || got.Version != 1 {
+			t.Errorf("got subject %q version %d", got.Subject, got.Version)
+		}
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		s, client, done := newConflictDraftServer(t)
+		defer done()
+
+		base := s.draft
+		s.edit(func(d *Draft) { d.Body = "their body" })
+		got, err := client.UpdateDraftMerged(context.Background(), base, UpdateDraftRequest{
+			Subject: String("New subject"),
+		}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := s.draft
+		want.Subject = "New subject"
+		want.Body = "their body"
+		want.Version = 2
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("draft: (-got +want):\n%s", diff)
+		}
+	})
+
+	t.Run("same change", func(t *testing.T) {
+		s, client, done := newConflictDraftServer(t)
+		defer done()
+
+		base := s.draft
+		s.edit(func(d *Draft) { d.Subject = "New subject" })
+		got, err := client.UpdateDraftMerged(context.Background(), base, UpdateDraftRequest{
+			Subject: String("New subject"),
+		}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Version != 2 {
+			t.Errorf("got version %d, want 2", got.Version)
+		}
+	})
+
+	t.Run("overlapping", func(t *testing.T) {
+		s, client, done := newConflictDraftServer(t)
+		defer done()
+
+		base := s.draft
+		s.edit(func(d *Draft) {
+			d.Body = "their body"
+			d.To = []Participant{{Email: "other@example.org"}}
+		})
+		_, err := client.UpdateDraftMerged(context.Background(), base, UpdateDraftRequest{
+			Subject: String("New subject"),
+			To:      &[]Participant{{Email: "mine@example.org"}},
+			Body:    String("my body"),
+		}, nil)
+		var conflict *DraftConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("expected *DraftConflictError, got: %v", err)
+		}
+		if diff := cmp.Diff(conflict.Fields, []string{"to", "body"}); diff != "" {
+			t.Errorf("fields: (-got +want):\n%s", diff)
+		}
+		if conflict.Current == nil || conflict.Current.Version != 1 {
+			t.Errorf("expected current draft version 1, got %+v", conflict.Current)
+		}
+		if !hasStatusCode(err, http.StatusConflict) {
+			t.Errorf("expected wrapped API error, got: %v", err)
+		}
+		if s.draft.Subject != "Subject" {
+			t.Errorf("draft was updated: %q", s.draft.Subject)
+		}
+	})
+
+	t.Run("surface", func(t *testing.T) {
+		s, client, done := newConflictDraftServer(t)
+		defer done()
+
+		base := s.draft
+		s.edit(func(d *Draft) { d.Body = "their body" })
+		_, err := client.UpdateDraftMerged(context.Background(), base, UpdateDraftRequest{
+			Subject: String("New subject"),
+		}, &DraftConflictOptions{Surface: true})
+		var conflict *DraftConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("expected *DraftConflictError, got: %v", err)
+		}
+		if len(conflict.Fields) != 0 {
+			t.Errorf("unexpected fields: %v", conflict.Fields)
+		}
+	})
+}
+
+func TestSendDraftLatest(t *testing.T) {
+	s, client, done := newConflictDraftServer(t)
+	defer done()
+
+	s.edit(func(d *Draft) { d.Body = "their body" })
+	// SendDraft returns the API error unchanged.
+	_, err := client.SendDraft(context.Background(), "draft1", 0)
+	if _, ok := err.(*Error); !ok || !hasStatusCode(err, http.StatusConflict) {
+		t.Fatalf("expected *Error conflict, got: %v", err)
+	}
+
+	msg, err := client.SendDraftLatest(context.Background(), "draft1", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.sent || msg.Body != "their body" {
+		t.Errorf("expected latest draft to be sent, got %+v", msg)
+	}
+}
+
+func TestDeleteDraftLatest(t *testing.T) {
+	s, client, done := newConflictDraftServer(t)
+	defer done()
+
+	s.edit(func(d *Draft) { d.Body = "their body" })
+	err := client.DeleteDraftLatest(context.Background(), "draft1", 0, &DraftConflictOptions{Surface: true})
+	if !isDraftConflict(err) {
+		t.Fatalf("expected *DraftConflictError, got: %v", err)
+	}
+
+	if err := client.DeleteDraftLatest(context.Background(), "draft1", 0, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.draft.ID != "" {
+		t.Errorf("expected draft to be deleted")
+	}
+}
+
+func TestConflictingDraftFields(t *testing.T) {
+	files := func(ids ...string) []File {
+		var fs []File
+		for _, id := range ids {
+			fs = append(fs, File{ID: id})
+		}
+		return fs
+	}
+	base := Draft{Message: Message{Subject: "Subject", Files: files("f1", "f2")}}
+
+	tests := map[string]struct {
+		current Draft
+		req     UpdateDraftRequest
+		want    []string
+	}{
+		"files reordered": {
+			current: Draft{Message: Message{Subject: "Subject", Files: files("f2", "f1")}},
+			req:     UpdateDraftRequest{FileIDs: &[]string{"f1", "f2", "f3"}},
+		},
+		"files changed": {
+			current: Draft{Message: Message{Subject: "Subject", Files: files("f1")}},
+			req:     UpdateDraftRequest{FileIDs: &[]string{"f1", "f2", "f3"}},
+			want:    []string{"file_ids"},
+		},
+		"same files added": {
+			current: Draft{Message: Message{Subject: "Subject", Files: files("f3", "f1", "f2")}},
+			req:     UpdateDraftRequest{FileIDs: &[]string{"f1", "f2", "f3"}},
+		},
+		"other field changed": {
+			current: Draft{Message: Message{Subject: "Theirs", Files: files("f1", "f2")}},
+			req:     UpdateDraftRequest{FileIDs: &[]string{"f1"}},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got := conflictingDraftFields(base, tt.current, tt.req)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("conflictingDraftFields: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func isDraftConflict(err error) bool {
+	var conflict *DraftConflictError
+	return errors.As(err, &conflict)
+}
@@ -2,6 +2,7 @@ package nylas
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -41,3 +42,18 @@ func NewError(resp *http.Response) error {
 	}
 	return &apiErr
 }
+
+// hasStatusCode reports whether err is an API Error with any of the given
+// HTTP status codes.
+func hasStatusCode(err error, codes ...int) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,12 @@ package nylas
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -31,6 +35,8 @@ type EventTimeSubobject interface {
 // EventTime subobject corresponds a single moment in time, which has no duration.
 // Reminders or alarms are represented as time subobjects.
 type EventTime struct {
+	// The object discriminator as returned by the API, usually "time".
+	Object string `json:"object"`
 	// Event time in UTC.
 	Time time.Time `json:"time"`
 	// If timezone is present, then the value for time will be read with timezone. Timezone using IANA formatted string.
@@ -42,6 +48,8 @@ func (t *EventTime) isEventTimeSubobject() {}
 // EventTimespan represents a span of time with a specific beginning and end time.
 // An hour lunch meeting would be represented as timespan subobjects.
 type EventTimespan struct {
+	// The object discriminator as returned by the API, usually "timespan".
+	Object string `json:"object"`
 	// The start time of the event.
 	StartTime time.Time `json:"start_time"`
 	// The end time of the event.
@@ -57,7 +65,9 @@ func (t *EventTimespan) isEventTimeSubobject() {}
 // EventDate represents a specific date for an event, without a clock-based start or end time.
 // Your birthday and holidays would be represented as date subobjects.
 type EventDate struct {
-	Date time.Time `json:"date"`
+	// The object discriminator as returned by the API, usually "date".
+	Object string    `json:"object"`
+	Date   time.Time `json:"date"`
 }
 
 func (t *EventDate) isEventTimeSubobject() {}
@@ -65,6 +75,8 @@ func (t *EventDate) isEventTimeSubobject() {}
 // EventDatespan a span of entire days without specific times.
 // A business quarter or academic semester would be represented as datespan subobjects.
 type EventDatespan struct {
+	// The object discriminator as returned by the API, usually "datespan".
+	Object string `json:"object"`
 	// The start date of the event.
 	StartDate time.Time `json:"start_date"`
 	// The end date of the event.
@@ -120,16 +132,34 @@ type Event struct {
 	MasterEventID string `json:"master_event_id"`
 	// Only included in exceptions (overrides) to recurring events, the start time of the recurring event.
 	OriginalStartTime time.Time `json:"original_start_time"`
-	// A key-value pair added to an event object to store data
-	Metadata json.RawMessage `json:"metadata"`
+	// Key-value pairs stored on the event, which can be used to filter
+	// events with EventsOptions.
+	Metadata map[string]string `json:"metadata"`
+	// Video conference details, see FindConferencing for events with join
+	// links in their Location or Description instead.
+	Conferencing *Conferencing `json:"conferencing"`
 }
 
+// Event time subobject discriminators.
+const (
+	EventWhenTime     = "time"
+	EventWhenTimespan = "timespan"
+	EventWhenDate     = "date"
+	EventWhenDatespan = "datespan"
+)
+
 // UnmarshalJSON defines an Event unmarshaller that infers the `when` subobject.
+//
+// The subobject is chosen by its object discriminator, or inferred from its
+// fields when that is missing. Missing or empty time zones are left nil, see
+// the TimeZone method.
 func (e *Event) UnmarshalJSON(data []byte) error {
 	type EventAlias Event
 	ea := &struct {
 		*EventAlias
-		When map[string]interface{} `json:"when"`
+		When json.RawMessage `json:"when"`
+		// Unix timestamp rather than the RFC 3339 time.Time expects.
+		OriginalStartTime json.Number `json:"original_start_time"`
 	}{
 		EventAlias: (*EventAlias)(e),
 	}
@@ -138,95 +168,305 @@ func (e *Event) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	switch w := ea.When; {
-	case w["time"] != nil:
-		t, ok := w["time"].(float64)
-		if !ok {
-			return errors.New("invalid time for event time")
-		}
-
-		loc, err := time.LoadLocation(w["timezone"].(string))
+	if ea.OriginalStartTime != "" {
+		t, err := parseUnixNumber(ea.OriginalStartTime)
 		if err != nil {
-			return errors.New("invalid timezone for event time")
+			return fmt.Errorf("event original_start_time: %w", err)
 		}
+		e.OriginalStartTime = t
+	}
+
+	when, err := decodeEventWhen(ea.When)
+	if err != nil {
+		return err
+	}
+	e.When = when
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the `when`
+// subobject and times as Unix timestamps as returned by the API so events
+// can be stored and decoded again without loss.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type EventAlias Event
+	when, err := newEventWhenJSON(e.When, true)
+	if err != nil {
+		return nil, err
+	}
+	var originalStartTime json.Number
+	if !e.OriginalStartTime.IsZero() {
+		originalStartTime = unixNumber(e.OriginalStartTime, true)
+	}
+	return json.Marshal(struct {
+		EventAlias
+		When              *eventWhenJSON `json:"when,omitempty"`
+		OriginalStartTime json.Number    `json:"original_start_time,omitempty"`
+	}{
+		EventAlias:        EventAlias(e),
+		When:              when,
+		OriginalStartTime: originalStartTime,
+	})
+}
 
-		ea.EventAlias.When = &EventTime{
-			Time:     time.Unix(int64(t), 0),
-			Timezone: &TimeZone{Location: loc},
+// TimeZone returns the time zone of the event's When, falling back to the
+// calendar's time zone and then UTC when it has none. The calendar may be nil.
+func (e Event) TimeZone(cal *Calendar) *time.Location {
+	var tz *TimeZone
+	switch w := e.When.(type) {
+	case *EventTime:
+		tz = w.Timezone
+	case *EventTimespan:
+		tz = w.StartTimezone
+	}
+	if tz != nil && tz.Location != nil {
+		return tz.Location
+	}
+	if cal != nil && cal.TimeZone != nil && cal.TimeZone.Location != nil {
+		return cal.TimeZone.Location
+	}
+	return time.UTC
+}
+
+// decodeEventWhen decodes a `when` subobject, returning nil when it is
+// missing or empty.
+func decodeEventWhen(data json.RawMessage) (EventTimeSubobject, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("event when must be an object: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	w := eventWhenFields(fields)
+	object, err := w.string("object")
+	if err != nil {
+		return nil, err
+	}
+	kind := object
+	if kind == "" {
+		switch {
+		case w.has("time"):
+			kind = EventWhenTime
+		case w.has("start_time"):
+			kind = EventWhenTimespan
+		case w.has("date"):
+			kind = EventWhenDate
+		case w.has("start_date"):
+			kind = EventWhenDatespan
+		default:
+			return nil, fmt.Errorf("event when has no object or known fields: %s", data)
 		}
-	case w["start_time"] != nil:
-		st, ok := w["start_time"].(float64)
-		if !ok {
-			return errors.New("invalid start time for event timespan")
+	}
+
+	switch kind {
+	case EventWhenTime:
+		t, err := w.unix("time")
+		if err != nil {
+			return nil, err
 		}
-		et, ok := w["end_time"].(float64)
-		if !ok {
-			return errors.New("invalid end time for event timespan")
+		tz, err := w.timezone("timezone")
+		if err != nil {
+			return nil, err
 		}
-
-		sLoc, err := time.LoadLocation(w["start_timezone"].(string))
+		return &EventTime{Object: object, Time: t, Timezone: tz}, nil
+	case EventWhenTimespan:
+		st, err := w.unix("start_time")
 		if err != nil {
-			return errors.New("invalid start timezone for event timespan")
+			return nil, err
 		}
-
-		eLoc, err := time.LoadLocation(w["end_timezone"].(string))
+		et, err := w.unix("end_time")
 		if err != nil {
-			return errors.New("invalid end timezone for event timespan")
+			return nil, err
 		}
-
-		ea.EventAlias.When = &EventTimespan{
-			StartTime:     time.Unix(int64(st), 0),
-			EndTime:       time.Unix(int64(et), 0),
-			StartTimezone: &TimeZone{Location: sLoc},
-			EndTimezone:   &TimeZone{Location: eLoc},
+		stz, err := w.timezone("start_timezone")
+		if err != nil {
+			return nil, err
 		}
-	case w["date"] != nil:
-		t, err := time.Parse("2006-01-02", w["date"].(string))
+		etz, err := w.timezone("end_timezone")
 		if err != nil {
-			return errors.New("invalid date for event date")
+			return nil, err
 		}
-		ea.EventAlias.When = &EventDate{
-			Date: t,
+		return &EventTimespan{
+			Object:        object,
+			StartTime:     st,
+			EndTime:       et,
+			StartTimezone: stz,
+			EndTimezone:   etz,
+		}, nil
+	case EventWhenDate:
+		d, err := w.date("date")
+		if err != nil {
+			return nil, err
 		}
-	case w["start_date"] != nil:
-		st, err := time.Parse("2006-01-02", w["start_date"].(string))
+		return &EventDate{Object: object, Date: d}, nil
+	case EventWhenDatespan:
+		sd, err := w.date("start_date")
 		if err != nil {
-			return errors.New("invalid start date for event date")
+			return nil, err
 		}
-
-		et, err := time.Parse("2006-01-02", w["end_date"].(string))
+		ed, err := w.date("end_date")
 		if err != nil {
-			return errors.New("invalid end date for event date")
+			return nil, err
 		}
+		return &EventDatespan{Object: object, StartDate: sd, EndDate: ed}, nil
+	}
+	return nil, fmt.Errorf("event when has unsupported object %q", object)
+}
+
+// eventWhenFields are the raw fields of a `when` subobject.
+type eventWhenFields map[string]json.RawMessage
+
+func (w eventWhenFields) has(key string) bool {
+	v, ok := w[key]
+	return ok && string(v) != "null"
+}
+
+// string returns the string field, or "" when it is missing or null.
+func (w eventWhenFields) string(key string) (string, error) {
+	if !w.has(key) {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(w[key], &s); err != nil {
+		return "", fmt.Errorf("event when %s must be a string: %s", key, w[key])
+	}
+	return s, nil
+}
+
+// unix returns the required Unix timestamp field.
+func (w eventWhenFields) unix(key string) (time.Time, error) {
+	if !w.has(key) {
+		return time.Time{}, fmt.Errorf("event when is missing %s", key)
+	}
+	var n json.Number
+	if err := json.Unmarshal(w[key], &n); err != nil {
+		return time.Time{}, fmt.Errorf("event when %s must be a Unix timestamp: %s", key, w[key])
+	}
+	t, err := parseUnixNumber(n)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("event when %s: %w", key, err)
+	}
+	return t, nil
+}
 
-		ea.EventAlias.When = &EventDatespan{
-			StartDate: st,
-			EndDate:   et,
+// date returns the required YYYY-MM-DD date field.
+func (w eventWhenFields) date(key string) (time.Time, error) {
+	s, err := w.string(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if s == "" {
+		return time.Time{}, fmt.Errorf("event when is missing %s", key)
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("event when %s must be a YYYY-MM-DD date: %q", key, s)
+	}
+	return d, nil
+}
+
+// timezone returns the optional IANA time zone field, nil when it is missing
+// or empty.
+func (w eventWhenFields) timezone(key string) (*TimeZone, error) {
+	s, err := w.string(key)
+	if err != nil || s == "" {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return nil, fmt.Errorf("event when %s: %w", key, err)
+	}
+	return &TimeZone{Location: loc}, nil
+}
+
+// parseUnixNumber parses a Unix timestamp in seconds, keeping any fractional
+// seconds to nanosecond precision.
+func parseUnixNumber(n json.Number) (time.Time, error) {
+	s := string(n)
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+
+	// Parse decimals exactly rather than through a float64, which cannot
+	// represent current timestamps to nanosecond precision.
+	if i := strings.IndexByte(s, '.'); i > 0 && !strings.ContainsAny(s, "eE") {
+		frac := s[i+1:]
+		sec, err := strconv.ParseInt(s[:i], 10, 64)
+		if err == nil && frac != "" && len(frac) <= 9 && strings.Trim(frac, "0123456789") == "" {
+			nsec, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+			if strings.HasPrefix(s, "-") {
+				nsec = -nsec
+			}
+			return time.Unix(sec, nsec), nil
 		}
 	}
 
-	return nil
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != f || f >= math.MaxInt64 || f < math.MinInt64 {
+		return time.Time{}, fmt.Errorf("invalid Unix timestamp %s", s)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+// unixNumber formats t as a Unix timestamp in seconds, with fractional
+// seconds when exact is true.
+func unixNumber(t time.Time, exact bool) json.Number {
+	sec, nsec := t.Unix(), t.Nanosecond()
+	if !exact || nsec == 0 {
+		return json.Number(strconv.FormatInt(sec, 10))
+	}
+	sign := ""
+	if sec < 0 {
+		// Unix rounds down, so negative times count nanoseconds back
+		// towards zero.
+		sec, nsec, sign = -(sec + 1), 1e9-nsec, "-"
+	}
+	return json.Number(sign + strconv.FormatInt(sec, 10) + "." +
+		strings.TrimRight(fmt.Sprintf("%09d", nsec), "0"))
+}
+
+// MetadataPair filters events by a metadata key and value.
+type MetadataPair struct {
+	Key   string
+	Value string
+}
+
+// String returns the pair in the key:value format expected by the API.
+func (p MetadataPair) String() string {
+	return p.Key + ":" + p.Value
 }
 
 // EventsOptions represents request options.
 type EventsOptions struct {
-	ShowCancelled   string `url:"show_cancelled,omitempty"`
-	Limit           int    `url:"limit,omitempty"`
-	Offset          int    `url:"offset,omitempty"`
-	EventID         string `url:"event_id,omitempty"`
-	CalendarID      string `url:"calendar_id,omitempty"`
-	Title           string `url:"title,omitempty"`
-	Description     string `url:"description,omitempty"`
-	Location        string `url:"location,omitempty"`
-	StartsBefore    string `url:"starts_before,omitempty"`
-	StartsAfter     string `url:"starts_after,omitempty"`
-	EndsBefore      string `url:"ends_before,omitempty"`
-	EndsAfter       string `url:"ends_after,omitempty"`
-	MetadataKey     string `url:"metadata_key,omitempty"`
-	MetadataValue   string `url:"metadata_value,omitempty"`
-	ExpandRecurring bool   `url:"expand_recurring,omitempty"`
-	MetadataPair    string `url:"metadata_pair,omitempty"`
-	Busy            bool   `url:"busy,omitempty"`
+	// Return cancelled events, which are omitted by default.
+	ShowCancelled *bool  `url:"show_cancelled,omitempty"`
+	Limit         int    `url:"limit,omitempty"`
+	Offset        int    `url:"offset,omitempty"`
+	EventID       string `url:"event_id,omitempty"`
+	CalendarID    string `url:"calendar_id,omitempty"`
+	Title         string `url:"title,omitempty"`
+	Description   string `url:"description,omitempty"`
+	Location      string `url:"location,omitempty"`
+	// Return events starting or ending before or after these times, which
+	// are sent as Unix timestamps.
+	StartsBefore time.Time `url:"starts_before,unix,omitempty"`
+	StartsAfter  time.Time `url:"starts_after,unix,omitempty"`
+	EndsBefore   time.Time `url:"ends_before,unix,omitempty"`
+	EndsAfter    time.Time `url:"ends_after,unix,omitempty"`
+	// Return events with any of these metadata keys, values or key and value
+	// pairs.
+	MetadataKeys   []string       `url:"metadata_key,omitempty"`
+	MetadataValues []string       `url:"metadata_value,omitempty"`
+	MetadataPairs  []MetadataPair `url:"metadata_pair,omitempty"`
+	// Return each occurrence of recurring events rather than the master
+	// event.
+	ExpandRecurring *bool `url:"expand_recurring,omitempty"`
+	Busy            *bool `url:"busy,omitempty"`
 }
 
 // Events returns all events.
@@ -249,14 +489,202 @@ func (c *Client) Events(ctx context.Context, opts *EventsOptions) ([]Event, erro
 	return resp, c.do(req, &resp)
 }
 
-// Event returns an event by id.
+// EventsCount returns the count of events which match the filter specified by
+// parameters.
+// See: https://developer.nylas.com/docs/api/#get/events
+func (c *Client) EventsCount(ctx context.Context, opts *EventsOptions) (int, error) {
+	req, err := c.newUserRequest(ctx, http.MethodGet, "/events", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts == nil {
+		opts = &EventsOptions{}
+	}
+	vs, err := query.Values(opts)
+	if err != nil {
+		return 0, err
+	}
+	vs.Set("view", ViewCount)
+	appendQueryValues(req, vs)
+
+	var resp countResponse
+	return resp.Count, c.do(req, &resp)
+}
+
+// Event returns an event by id, expandRecurring requests the occurrence
+// rather than the master event when id refers to a recurring event.
 // See: https://developer.nylas.com/docs/api/#get/events/id
-func (c *Client) Event(ctx context.Context, id string) (Event, error) {
+func (c *Client) Event(ctx context.Context, id string, expandRecurring bool) (Event, error) {
 	req, err := c.newUserRequest(ctx, http.MethodGet, "/events/"+id, nil)
 	if err != nil {
 		return Event{}, err
 	}
 
+	if expandRecurring {
+		appendQueryValues(req, url.Values{"expand_recurring": {"true"}})
+	}
+
+	var resp Event
+	return resp, c.do(req, &resp)
+}
+
+// eventWhenJSON is the JSON encoding of an EventTimeSubobject, times are Unix
+// timestamps and dates are formatted as YYYY-MM-DD.
+type eventWhenJSON struct {
+	Object        string      `json:"object,omitempty"`
+	Time          json.Number `json:"time,omitempty"`
+	Timezone      *TimeZone   `json:"timezone,omitempty"`
+	StartTime     json.Number `json:"start_time,omitempty"`
+	EndTime       json.Number `json:"end_time,omitempty"`
+	StartTimezone *TimeZone   `json:"start_timezone,omitempty"`
+	EndTimezone   *TimeZone   `json:"end_timezone,omitempty"`
+	Date          string      `json:"date,omitempty"`
+	StartDate     string      `json:"start_date,omitempty"`
+	EndDate       string      `json:"end_date,omitempty"`
+}
+
+// newEventWhenJSON returns the encoding of when, exact includes the object
+// discriminator and fractional seconds which requests omit.
+func newEventWhenJSON(when EventTimeSubobject, exact bool) (*eventWhenJSON, error) {
+	var w *eventWhenJSON
+	switch when := when.(type) {
+	case *EventTime:
+		w = &eventWhenJSON{
+			Object:   when.Object,
+			Time:     unixNumber(when.Time, exact),
+			Timezone: when.Timezone,
+		}
+	case *EventTimespan:
+		w = &eventWhenJSON{
+			Object:        when.Object,
+			StartTime:     unixNumber(when.StartTime, exact),
+			EndTime:       unixNumber(when.EndTime, exact),
+			StartTimezone: when.StartTimezone,
+			EndTimezone:   when.EndTimezone,
+		}
+	case *EventDate:
+		w = &eventWhenJSON{Object: when.Object, Date: when.Date.Format("2006-01-02")}
+	case *EventDatespan:
+		w = &eventWhenJSON{
+			Object:    when.Object,
+			StartDate: when.StartDate.Format("2006-01-02"),
+			EndDate:   when.EndDate.Format("2006-01-02"),
+		}
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported event when %T", when)
+	}
+	if !exact {
+		w.Object = ""
+	}
+	return w, nil
+}
+
+// EventRequest contains the request parameters required to create an event.
+type EventRequest struct {
+	CalendarID   string             `json:"calendar_id"`
+	Title        string             `json:"title,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Location     string             `json:"location,omitempty"`
+	When         EventTimeSubobject `json:"-"`
+	Participants []EventParticipant `json:"participants,omitempty"`
+	Busy         *bool              `json:"busy,omitempty"`
+	Recurrence   *EventRecurrence   `json:"recurrence,omitempty"`
+	Metadata     map[string]string  `json:"metadata,omitempty"`
+	Conferencing *Conferencing      `json:"conferencing,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r EventRequest) MarshalJSON() ([]byte, error) {
+	type EventRequestAlias EventRequest
+	when, err := newEventWhenJSON(r.When, false)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		EventRequestAlias
+		When *eventWhenJSON `json:"when,omitempty"`
+	}{
+		EventRequestAlias: EventRequestAlias(r),
+		When:              when,
+	})
+}
+
+// CreateEvent creates a new event.
+// See: https://developer.nylas.com/docs/api/#post/events
+func (c *Client) CreateEvent(ctx context.Context, eventReq EventRequest) (Event, error) {
+	if err := validateMetadata(eventReq.Metadata); err != nil {
+		return Event{}, err
+	}
+
+	req, err := c.newUserRequest(ctx, http.MethodPost, "/events", &eventReq)
+	if err != nil {
+		return Event{}, err
+	}
+
+	var resp Event
+	return resp, c.do(req, &resp)
+}
+
+// UpdateEventRequest contains the request parameters required to update an
+// event, fields are optional and will overwrite previous values if given.
+type UpdateEventRequest struct {
+	Title        *string             `json:"title,omitempty"`
+	Description  *string             `json:"description,omitempty"`
+	Location     *string             `json:"location,omitempty"`
+	When         EventTimeSubobject  `json:"-"`
+	Participants *[]EventParticipant `json:"participants,omitempty"`
+	Busy         *bool               `json:"busy,omitempty"`
+	Recurrence   *EventRecurrence    `json:"recurrence,omitempty"`
+	Metadata     *map[string]string  `json:"metadata,omitempty"`
+	Conferencing *Conferencing       `json:"conferencing,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r UpdateEventRequest) MarshalJSON() ([]byte, error) {
+	type UpdateEventRequestAlias UpdateEventRequest
+	when, err := newEventWhenJSON(r.When, false)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		UpdateEventRequestAlias
+		When *eventWhenJSON `json:"when,omitempty"`
+	}{
+		UpdateEventRequestAlias: UpdateEventRequestAlias(r),
+		When:                    when,
+	})
+}
+
+// UpdateEvent updates an event with the id.
+// See: https://developer.nylas.com/docs/api/#put/events/id
+func (c *Client) UpdateEvent(
+	ctx context.Context, id string, updateReq UpdateEventRequest,
+) (Event, error) {
+	if updateReq.Metadata != nil {
+		if err := validateMetadata(*updateReq.Metadata); err != nil {
+			return Event{}, err
+		}
+	}
+
+	req, err := c.newUserRequest(ctx, http.MethodPut, "/events/"+id, &updateReq)
+	if err != nil {
+		return Event{}, err
+	}
+
 	var resp Event
 	return resp, c.do(req, &resp)
 }
+
+// SetEventMetadata replaces the metadata of the event with the id, a nil or
+// empty map removes all metadata.
+func (c *Client) SetEventMetadata(
+	ctx context.Context, id string, metadata map[string]string,
+) (Event, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return c.UpdateEvent(ctx, id, UpdateEventRequest{Metadata: &metadata})
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -41,9 +44,7 @@ func TestEvents(t *testing.T) {
 		t.Fatalf("loading timezone: %v", err)
 	}
 
-	meta := json.RawMessage(`{
-		"hello": "goodbye"
-	}`)
+	meta := map[string]string{"hello": "goodbye"}
 
 	want := []Event{
 		{
@@ -76,6 +77,10 @@ func TestEvents(t *testing.T) {
 				Timezone: &TimeZone{Location: loc},
 			},
 			Metadata: meta,
+			Conferencing: &Conferencing{
+				Provider: ConferencingProviderWebEx,
+				Details:  ConferencingDetails{URL: "string", Password: "string", PIN: "string"},
+			},
 		},
 	}
 
@@ -86,6 +91,68 @@ func TestEvents(t *testing.T) {
 
 var eventsJSON = []byte(fmt.Sprintf("[%s]", eventJSON))
 
+func TestEventsCount(t *testing.T) {
+	accessToken := "accessToken"
+	wantQuery := url.Values{
+		"busy":             {"false"},
+		"calendar_id":      {"{calendar_id}"},
+		"ends_before":      {"1583161200"},
+		"expand_recurring": {"true"},
+		"metadata_key":     {"a", "b"},
+		"metadata_pair":    {"team:sales", "region:emea"},
+		"show_cancelled":   {"false"},
+		"starts_after":     {"1583157600"},
+		"view":             {ViewCount},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/events")
+		assertQueryParams(t, r, wantQuery)
+		_, _ = w.Write([]byte(`{"count":2}`))
+	}))
+	defer ts.Close()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.EventsCount(context.Background(), &EventsOptions{
+		ShowCancelled:   Bool(false),
+		CalendarID:      "{calendar_id}",
+		StartsAfter:     time.Date(2020, 3, 2, 9, 0, 0, 0, loc),
+		EndsBefore:      time.Date(2020, 3, 2, 15, 0, 0, 0, time.UTC),
+		MetadataKeys:    []string{"a", "b"},
+		MetadataPairs:   []MetadataPair{{Key: "team", Value: "sales"}, {Key: "region", Value: "emea"}},
+		ExpandRecurring: Bool(true),
+		Busy:            Bool(false),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("count: got %d; want 2", got)
+	}
+}
+
+func TestEventExpandRecurring(t *testing.T) {
+	accessToken := "accessToken"
+	id := "{event_id}"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/events/"+id)
+		assertQueryParams(t, r, url.Values{"expand_recurring": {"true"}})
+		_, _ = w.Write(eventJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	if _, err := client.Event(context.Background(), id, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestEvent(t *testing.T) {
 	accessToken := "accessToken"
 
@@ -98,7 +165,7 @@ func TestEvent(t *testing.T) {
 	defer ts.Close()
 
 	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
-	got, err := client.Event(context.Background(), id)
+	got, err := client.Event(context.Background(), id, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -108,9 +175,7 @@ func TestEvent(t *testing.T) {
 		t.Fatalf("loading timezone: %v", err)
 	}
 
-	meta := json.RawMessage(`{
-		"hello": "goodbye"
-	}`)
+	meta := map[string]string{"hello": "goodbye"}
 
 	want := Event{
 		ID:          "{event_id}",
@@ -142,6 +207,10 @@ func TestEvent(t *testing.T) {
 			Timezone: &TimeZone{Location: loc},
 		},
 		Metadata: meta,
+		Conferencing: &Conferencing{
+			Provider: ConferencingProviderWebEx,
+			Details:  ConferencingDetails{URL: "string", Password: "string", PIN: "string"},
+		},
 	}
 
 	if diff := cmp.Diff(got, want, cmp.Comparer(compareTimeZones)); diff != "" {
@@ -149,6 +218,448 @@ func TestEvent(t *testing.T) {
 	}
 }
 
+func TestEventUnmarshalOverride(t *testing.T) {
+	var got Event
+	err := json.Unmarshal([]byte(`{
+		"id": "override",
+		"master_event_id": "master",
+		"original_start_time": 1409594400
+	}`), &got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Event{
+		ID:                "override",
+		MasterEventID:     "master",
+		OriginalStartTime: time.Unix(1409594400, 0),
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Event: (-got +want):\n%s", diff)
+	}
+}
+
+func TestCreateEvent(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := []byte(`{"calendar_id":"{calendar_id}","title":"Lunch",` +
+		`"participants":[{"name":"","email":"ada@example.com","status":"","comment":""}],"busy":false,` +
+		`"when":{"start_time":1583157600,"end_time":1583161200,` +
+		`"start_timezone":"America/New_York","end_timezone":"America/New_York"}}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/events")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(eventJSON)
+	}))
+	defer ts.Close()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	busy := false
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.CreateEvent(context.Background(), EventRequest{
+		CalendarID: "{calendar_id}",
+		Title:      "Lunch",
+		When: &EventTimespan{
+			StartTime:     time.Date(2020, 3, 2, 9, 0, 0, 0, loc),
+			EndTime:       time.Date(2020, 3, 2, 10, 0, 0, 0, loc),
+			StartTimezone: &TimeZone{Location: loc},
+			EndTimezone:   &TimeZone{Location: loc},
+		},
+		Participants: []EventParticipant{{Email: "ada@example.com"}},
+		Busy:         &busy,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != "{event_id}" {
+		t.Errorf("CreateEvent: got ID %q", got.ID)
+	}
+}
+
+func TestEventRequestWhenJSON(t *testing.T) {
+	tests := map[string]struct {
+		when EventTimeSubobject
+		want string
+	}{
+		"none": {nil, `{"calendar_id":""}`},
+		"time": {
+			&EventTime{Time: time.Unix(1583139600, 0)},
+			`{"calendar_id":"","when":{"time":1583139600}}`,
+		},
+		"date": {
+			&EventDate{Date: civilDate(2020, 3, 2)},
+			`{"calendar_id":"","when":{"date":"2020-03-02"}}`,
+		},
+		"datespan": {
+			&EventDatespan{StartDate: civilDate(2020, 3, 2), EndDate: civilDate(2020, 3, 4)},
+			`{"calendar_id":"","when":{"start_date":"2020-03-02","end_date":"2020-03-04"}}`,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, err := json.Marshal(EventRequest{When: tt.when})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Errorf("EventRequest: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateEvent(t *testing.T) {
+	accessToken := "accessToken"
+	id := "{event_id}"
+	wantBody := []byte(`{"title":"Lunch","metadata":{"external_id":"123"},` +
+		`"when":{"date":"2020-03-02"}}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPut, "/events/"+id)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(eventJSON)
+	}))
+	defer ts.Close()
+
+	title := "Lunch"
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	_, err := client.UpdateEvent(context.Background(), id, UpdateEventRequest{
+		Title:    &title,
+		When:     &EventDate{Date: civilDate(2020, 3, 2)},
+		Metadata: &map[string]string{"external_id": "123"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetEventMetadata(t *testing.T) {
+	accessToken := "accessToken"
+	id := "{event_id}"
+	wantBody := []byte(`{"metadata":{}}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPut, "/events/"+id)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(body, wantBody); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+
+		_, _ = w.Write(eventJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.SetEventMetadata(context.Background(), id, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got.Metadata, map[string]string{"hello": "goodbye"}); diff != "" {
+		t.Errorf("Metadata: (-got +want):\n%s", diff)
+	}
+}
+
+func TestEventInvalidMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	metadata := map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "value"}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	if _, err := client.CreateEvent(context.Background(), EventRequest{Metadata: metadata}); err == nil {
+		t.Error("CreateEvent: expected error")
+	}
+	if _, err := client.SetEventMetadata(context.Background(), "id", metadata); err == nil {
+		t.Error("SetEventMetadata: expected error")
+	}
+}
+
+func TestEventUnmarshalWhen(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	nyTZ := &TimeZone{Location: ny}
+
+	tests := map[string]struct {
+		when string
+		want EventTimeSubobject
+	}{
+		"missing":  {``, nil},
+		"null":     {`"when": null,`, nil},
+		"empty":    {`"when": {},`, nil},
+		"time":     {`"when": {"time": 1583157600, "timezone": "America/New_York"},`, &EventTime{Time: time.Unix(1583157600, 0), Timezone: nyTZ}},
+		"time UTC": {`"when": {"object": "time", "time": 1583157600},`, &EventTime{Object: "time", Time: time.Unix(1583157600, 0)}},
+		"empty timezone": {
+			`"when": {"time": 1583157600, "timezone": ""},`,
+			&EventTime{Time: time.Unix(1583157600, 0)},
+		},
+		"fractional": {
+			`"when": {"time": 1583157600.123456789},`,
+			&EventTime{Time: time.Unix(1583157600, 123456789)},
+		},
+		"exponent": {
+			`"when": {"time": 1.5831576e9},`,
+			&EventTime{Time: time.Unix(1583157600, 0)},
+		},
+		"quoted": {
+			`"when": {"time": "1583157600"},`,
+			&EventTime{Time: time.Unix(1583157600, 0)},
+		},
+		"timespan": {
+			`"when": {"object": "timespan", "start_time": 1583157600, "end_time": 1583161200, "start_timezone": "America/New_York"},`,
+			&EventTimespan{
+				Object:        "timespan",
+				StartTime:     time.Unix(1583157600, 0),
+				EndTime:       time.Unix(1583161200, 0),
+				StartTimezone: nyTZ,
+			},
+		},
+		"date": {
+			`"when": {"object": "date", "date": "2020-03-02"},`,
+			&EventDate{Object: "date", Date: civilDate(2020, 3, 2)},
+		},
+		"datespan": {
+			`"when": {"start_date": "2020-03-02", "end_date": "2020-03-04"},`,
+			&EventDatespan{StartDate: civilDate(2020, 3, 2), EndDate: civilDate(2020, 3, 4)},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var got Event
+			if err := json.Unmarshal([]byte(`{`+tt.when+`"id": "event"}`), &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(got.When, tt.want, cmp.Comparer(compareTimeZones)); diff != "" {
+				t.Errorf("When: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEventUnmarshalWhenInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not an object":     `[]`,
+		"unknown shape":     `{"start": 1583157600}`,
+		"unknown object":    `{"object": "instant", "time": 1583157600}`,
+		"object not string": `{"object": 1, "time": 1583157600}`,
+		"object mismatch":   `{"object": "date", "time": 1583157600}`,
+		"time not number":   `{"time": "noon"}`,
+		"time bool":         `{"time": true}`,
+		"time too large":    `{"time": 1e300}`,
+		"missing end time":  `{"start_time": 1583157600}`,
+		"timezone number":   `{"time": 1583157600, "timezone": 5}`,
+		"unknown timezone":  `{"time": 1583157600, "timezone": "Mars/Olympus_Mons"}`,
+		"date format":       `{"date": "02/03/2020"}`,
+		"date number":       `{"date": 20200302}`,
+		"missing end date":  `{"start_date": "2020-03-02"}`,
+		"original start":    `null, "original_start_time": "yesterday"`,
+	}
+
+	for desc, when := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var e Event
+			if err := json.Unmarshal([]byte(`{"when": `+when+`}`), &e); err == nil {
+				t.Errorf("expected error, got %+v", e.When)
+			}
+		})
+	}
+}
+
+// randomEventWhen returns a random when subobject with times at nanosecond
+// precision.
+func randomEventWhen(r *rand.Rand, locs []*time.Location) EventTimeSubobject {
+	randomTime := func() time.Time {
+		return time.Unix(r.Int63n(4e9)-1e9, r.Int63n(1e9))
+	}
+	randomTZ := func() *TimeZone {
+		if i := r.Intn(len(locs) + 1); i < len(locs) {
+			return &TimeZone{Location: locs[i]}
+		}
+		return nil
+	}
+	randomDate := func() time.Time {
+		return civilDate(1970+r.Intn(100), time.Month(1+r.Intn(12)), 1+r.Intn(28))
+	}
+	objects := []string{"", EventWhenTime, EventWhenTimespan, EventWhenDate, EventWhenDatespan}
+	object := func(i int) string {
+		if r.Intn(2) == 0 {
+			return ""
+		}
+		return objects[i]
+	}
+
+	switch r.Intn(5) {
+	case 1:
+		return &EventTime{Object: object(1), Time: randomTime(), Timezone: randomTZ()}
+	case 2:
+		start := randomTime()
+		return &EventTimespan{
+			Object:        object(2),
+			StartTime:     start,
+			EndTime:       start.Add(time.Duration(r.Int63n(int64(48 * time.Hour)))),
+			StartTimezone: randomTZ(),
+			EndTimezone:   randomTZ(),
+		}
+	case 3:
+		return &EventDate{Object: object(3), Date: randomDate()}
+	case 4:
+		start := randomDate()
+		return &EventDatespan{Object: object(4), StartDate: start, EndDate: start.AddDate(0, 0, r.Intn(90))}
+	}
+	return nil
+}
+
+func TestEventJSONRoundTrip(t *testing.T) {
+	var locs []*time.Location
+	for _, name := range []string{"UTC", "America/New_York", "Asia/Kolkata", "Australia/Lord_Howe"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatalf("loading timezone: %v", err)
+		}
+		locs = append(locs, loc)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		want := Event{
+			ID:       fmt.Sprintf("event%d", i),
+			When:     randomEventWhen(r, locs),
+			Metadata: map[string]string{"key": "value"},
+		}
+		if r.Intn(2) == 0 {
+			want.MasterEventID = "master"
+			want.OriginalStartTime = time.Unix(r.Int63n(4e9)-1e9, r.Int63n(1e9))
+		}
+
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got Event
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("unmarshal %s: %v", data, err)
+		}
+		if diff := cmp.Diff(got, want, cmp.Comparer(compareTimeZones)); diff != "" {
+			t.Fatalf("round trip %s: (-got +want):\n%s", data, diff)
+		}
+	}
+}
+
+// TestEventUnmarshalWhenFuzz decodes randomly corrupted events and when
+// subobjects built from valid and invalid field values, which must return
+// an error rather than panic.
+func TestEventUnmarshalWhenFuzz(t *testing.T) {
+	keys := []string{
+		"object", "time", "timezone", "start_time", "end_time",
+		"start_timezone", "end_timezone", "date", "start_date", "end_date",
+	}
+	values := []string{
+		`null`, `true`, `0`, `-1`, `1583157600`, `1583157600.5`, `-0.000000001`,
+		`1e308`, `-1e308`, `"1583157600"`, `""`, `"time"`, `"timespan"`, `"date"`,
+		`"datespan"`, `"America/New_York"`, `"Local"`, `"../../etc/passwd"`,
+		`"2020-03-02"`, `"2020-02-30"`, `[]`, `{}`, `[1583157600]`, `{"time": 1}`,
+	}
+
+	r := rand.New(rand.NewSource(1))
+	valid, err := json.Marshal(Event{
+		When: &EventTimespan{
+			Object:    "timespan",
+			StartTime: time.Unix(1583157600, 5),
+			EndTime:   time.Unix(1583161200, 0),
+		},
+		OriginalStartTime: time.Unix(1583157600, 0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 5000; i++ {
+		var data []byte
+		if i%2 == 0 {
+			fields := make([]string, r.Intn(len(keys)))
+			for j := range fields {
+				fields[j] = fmt.Sprintf("%q: %s", keys[r.Intn(len(keys))], values[r.Intn(len(values))])
+			}
+			data = []byte(`{"when": {` + strings.Join(fields, ", ") + `}}`)
+		} else {
+			data = append([]byte(nil), valid...)
+			for j := r.Intn(4); j >= 0; j-- {
+				data[r.Intn(len(data))] = "{}[]\":,.-0123456789e"[r.Intn(20)]
+			}
+		}
+
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Fatalf("panic decoding %s: %v", data, p)
+				}
+			}()
+			var e Event
+			_ = json.Unmarshal(data, &e)
+		}()
+	}
+}
+
+func TestEventTimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	cal := &Calendar{TimeZone: &TimeZone{Location: kolkata}}
+
+	tests := map[string]struct {
+		event Event
+		cal   *Calendar
+		want  *time.Location
+	}{
+		"time":        {Event{When: &EventTime{Timezone: &TimeZone{Location: ny}}}, cal, ny},
+		"timespan":    {Event{When: &EventTimespan{StartTimezone: &TimeZone{Location: ny}}}, nil, ny},
+		"calendar":    {Event{When: &EventTime{}}, cal, kolkata},
+		"date":        {Event{When: &EventDate{}}, cal, kolkata},
+		"no calendar": {Event{When: &EventDatespan{}}, nil, time.UTC},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := tt.event.TimeZone(tt.cal); got != tt.want {
+				t.Errorf("TimeZone: got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 var eventJSON = []byte(`{
 	"account_id": "{account_id}",
 	"busy": true,
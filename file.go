@@ -1,15 +1,19 @@
 package nylas
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"golang.org/x/sync/errgroup"
@@ -108,12 +112,117 @@ func (c *Client) UploadFile(
 //
 // See: https://docs.nylas.com/reference#filesiddownload
 func (c *Client) DownloadFile(ctx context.Context, id string) (io.ReadCloser, error) {
+	return c.DownloadFileWithOptions(ctx, id, nil)
+}
+
+// ErrChecksumMismatch is returned when reading a FileDownload to EOF if the
+// content does not match the expected DownloadFileOptions.Checksum.
+var ErrChecksumMismatch = errors.New("download checksum mismatch")
+
+// DownloadFileOptions provides optional parameters to DownloadFileWithOptions.
+type DownloadFileOptions struct {
+	// Offset of the first byte to download, used to resume an interrupted
+	// download with an HTTP Range request.
+	Offset int64
+	// Length of the range to download, zero downloads the rest of the file.
+	Length int64
+
+	// Progress is called after each read with the position reached in the
+	// file, including the Offset, and the file size or -1 when unknown.
+	Progress func(pos, size int64)
+
+	// Hash is written the downloaded content and its sum compared against
+	// Checksum once the body has been read to EOF. When resuming, pass a
+	// hash which has already been written the previously downloaded bytes.
+	// As the checksum is of the whole file, Hash cannot be used with a
+	// Length.
+	Hash     hash.Hash
+	Checksum []byte
+}
+
+// FileDownload is the body of a file download along with the details of the
+// content returned in the response headers.
+type FileDownload struct {
+	// ContentType of the file.
+	ContentType string
+	// ContentLength of this download, -1 when unknown.
+	ContentLength int64
+	// Offset of the first downloaded byte within the file.
+	Offset int64
+	// Size of the whole file, -1 when unknown.
+	Size int64
+	// Filename from the Content-Disposition header, if any.
+	Filename string
+
+	body     io.ReadCloser
+	pos      int64
+	progress func(pos, size int64)
+	hash     hash.Hash
+	checksum []byte
+}
+
+// Read implements the io.Reader interface, returning ErrChecksumMismatch
+// instead of io.EOF if the content does not match the expected checksum.
+func (d *FileDownload) Read(p []byte) (int, error) {
+	n, err := d.body.Read(p)
+	if n > 0 {
+		d.pos += int64(n)
+		if d.hash != nil {
+			d.hash.Write(p[:n]) // nolint: errcheck
+		}
+		if d.progress != nil {
+			d.progress(d.pos, d.Size)
+		}
+	}
+	if err == io.EOF && d.hash != nil && !bytes.Equal(d.hash.Sum(nil), d.checksum) {
+		err = ErrChecksumMismatch
+	}
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (d *FileDownload) Close() error {
+	return d.body.Close()
+}
+
+// DownloadFileWithOptions downloads a file attachment, optionally resuming
+// from an offset, reporting progress and verifying a checksum.
+//
+// If the returned error is nil, you are expected to read the FileDownload to
+// EOF and close.
+//
+// See: https://docs.nylas.com/reference#filesiddownload
+func (c *Client) DownloadFileWithOptions(
+	ctx context.Context, id string, opts *DownloadFileOptions,
+) (*FileDownload, error) {
+	if opts == nil {
+		opts = &DownloadFileOptions{}
+	}
+	if opts.Offset < 0 || opts.Length < 0 {
+		return nil, errors.New("negative download offset or length")
+	}
+	if (opts.Hash == nil) != (opts.Checksum == nil) {
+		return nil, errors.New("download hash and checksum must be set together")
+	}
+	if opts.Hash != nil && opts.Length > 0 {
+		return nil, errors.New("download hash cannot be used with a length")
+	}
+
 	endpoint := fmt.Sprintf("/files/%s/download", id)
 	req, err := c.newUserRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	ranged := opts.Offset > 0 || opts.Length > 0
+	if ranged {
+		r := fmt.Sprintf("bytes=%d-", opts.Offset)
+		if opts.Length > 0 {
+			r += strconv.FormatInt(opts.Offset+opts.Length-1, 10)
+		}
+		req.Header.Set("Range", r)
+	}
+
 	resp, err := c.c.Do(req)
 	if err != nil {
 		return nil, err
@@ -121,9 +230,70 @@ func (c *Client) DownloadFile(ctx context.Context, id string) (io.ReadCloser, er
 
 	if resp.StatusCode > 299 {
 		defer resp.Body.Close() // nolint: errcheck
-		return nil, NewError(resp)
+		return nil, c.responseError(resp)
+	}
+
+	d := &FileDownload{
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		Size:          resp.ContentLength,
+		body:          resp.Body,
+		progress:      opts.Progress,
+		hash:          opts.Hash,
+		checksum:      opts.Checksum,
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		d.Filename = params["filename"]
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			resp.Body.Close() // nolint: errcheck
+			return nil, err
+		}
+		d.Offset, d.Size = start, size
+	} else if ranged {
+		// The server ignored the Range header and sent the whole file, so
+		// skip to the requested range ourselves.
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, opts.Offset); err != nil {
+			resp.Body.Close() // nolint: errcheck
+			return nil, err
+		}
+		d.Offset = opts.Offset
+		if d.ContentLength >= 0 {
+			d.ContentLength -= opts.Offset
+		}
+		if opts.Length > 0 {
+			d.body = struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(resp.Body, opts.Length), resp.Body}
+			if d.ContentLength < 0 || d.ContentLength > opts.Length {
+				d.ContentLength = opts.Length
+			}
+		}
+	}
+	d.pos = d.Offset
+	return d, nil
+}
+
+// parseContentRange parses a "bytes start-end/size" Content-Range header
+// value, returning a size of -1 when the size is unknown.
+func parseContentRange(v string) (start, size int64, err error) {
+	var end int64
+	var total string
+	if _, err := fmt.Sscanf(v, "bytes %d-%d/%s", &start, &end, &total); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", v)
+	}
+	if total == "*" {
+		return start, -1, nil
+	}
+	size, err = strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", v)
 	}
-	return resp.Body, nil
+	return start, size, nil
 }
 
 // DeleteFile removes an existing file identified by the specified file ID.
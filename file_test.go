@@ -2,6 +2,7 @@ package nylas
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"image/png"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -134,6 +136,119 @@ func TestDownloadFile(t *testing.T) {
 	}
 }
 
+func TestDownloadFileWithOptions(t *testing.T) {
+	accessToken := "accessToken"
+	id := "br57kcekhf1hsjq04y8aonkit"
+	content := "0123456789"
+	sum := sha256.Sum256([]byte(content))
+
+	tests := map[string]struct {
+		ignoreRange bool
+		opts        *DownloadFileOptions
+		wantRange   string
+		want        string
+		wantErr     error
+		wantOffset  int64
+		wantLength  int64
+	}{
+		"whole file": {
+			opts: &DownloadFileOptions{Hash: sha256.New(), Checksum: sum[:]},
+			want: content, wantLength: 10,
+		},
+		"resume": {
+			opts:      &DownloadFileOptions{Offset: 4},
+			wantRange: "bytes=4-",
+			want:      "456789", wantOffset: 4, wantLength: 6,
+		},
+		"range": {
+			opts:      &DownloadFileOptions{Offset: 2, Length: 3},
+			wantRange: "bytes=2-4",
+			want:      "234", wantOffset: 2, wantLength: 3,
+		},
+		"range ignored": {
+			ignoreRange: true,
+			opts:        &DownloadFileOptions{Offset: 2, Length: 3},
+			wantRange:   "bytes=2-4",
+			want:        "234", wantOffset: 2, wantLength: 3,
+		},
+		"checksum mismatch": {
+			opts:    &DownloadFileOptions{Hash: sha256.New(), Checksum: []byte("nope")},
+			want:    content,
+			wantErr: ErrChecksumMismatch, wantLength: 10,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assertBasicAuth(t, r, accessToken, "")
+				assertMethodPath(t, r, http.MethodGet, "/files/"+id+"/download")
+				if v := r.Header.Get("Range"); v != tt.wantRange {
+					t.Errorf("unexpected range header: %q", v)
+				}
+				if tt.ignoreRange {
+					r.Header.Del("Range")
+				}
+
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Disposition", `attachment; filename="digits.txt"`)
+				http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+			}))
+			defer ts.Close()
+
+			var progress []int64
+			tt.opts.Progress = func(pos, size int64) {
+				if size != int64(len(content)) {
+					t.Errorf("unexpected progress size: %d", size)
+				}
+				progress = append(progress, pos)
+			}
+
+			client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+			file, err := client.DownloadFileWithOptions(context.Background(), id, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer file.Close()
+
+			data, err := ioutil.ReadAll(file)
+			if err != tt.wantErr {
+				t.Fatalf("unexpected read error: %v", err)
+			}
+			if diff := cmp.Diff(string(data), tt.want); diff != "" {
+				t.Errorf("File: (-got +want):\n%s", diff)
+			}
+			if file.ContentType != "text/plain" || file.Filename != "digits.txt" {
+				t.Errorf("unexpected content type/filename: %q %q", file.ContentType, file.Filename)
+			}
+			if file.Offset != tt.wantOffset || file.ContentLength != tt.wantLength {
+				t.Errorf("unexpected offset/length: %d %d", file.Offset, file.ContentLength)
+			}
+			if len(progress) == 0 || progress[len(progress)-1] != tt.wantOffset+int64(len(tt.want)) {
+				t.Errorf("unexpected progress: %v", progress)
+			}
+		})
+	}
+}
+
+func TestDownloadFileWithOptionsInvalid(t *testing.T) {
+	sum := sha256.Sum256([]byte("0123456789"))
+	tests := map[string]*DownloadFileOptions{
+		"negative offset":  {Offset: -1},
+		"hash only":        {Hash: sha256.New()},
+		"hash with length": {Length: 3, Hash: sha256.New(), Checksum: sum[:]},
+	}
+
+	client := NewClient("", "", WithAccessToken("accessToken"))
+	for desc, opts := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := client.DownloadFileWithOptions(context.Background(), "id", opts); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
 func TestDeleteFile(t *testing.T) {
 	accessToken := "accessToken"
 	id := "br57kcekhf1hsjq04y8aonkit"
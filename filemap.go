@@ -0,0 +1,103 @@
+package nylas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileMap is a map kept in a JSON file, which is rewritten atomically on
+// every change. Changes are undone if the file cannot be written. It is not
+// safe for concurrent use, the stores using it hold their own lock.
+type fileMap[V any] struct {
+	path string
+	m    map[string]V
+	// seal transforms the JSON before it is written and open reverses it
+	// after the file is read, such as to encrypt the file. Both are
+	// optional.
+	seal func([]byte) ([]byte, error)
+	open func([]byte) ([]byte, error)
+}
+
+// loadFileMap returns the fileMap read from the file at path, which is empty
+// if the file does not exist.
+func loadFileMap[V any](
+	path string, seal, open func([]byte) ([]byte, error),
+) (*fileMap[V], error) {
+	f := &fileMap[V]{path: path, m: make(map[string]V), seal: seal, open: open}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if f.open != nil {
+		if data, err = f.open(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(data, &f.m); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// set sets the value of key and writes the file.
+func (f *fileMap[V]) set(key string, v V) error {
+	prev, ok := f.m[key]
+	f.m[key] = v
+	if err := f.save(); err != nil {
+		if ok {
+			f.m[key] = prev
+		} else {
+			delete(f.m, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// delete removes key and writes the file, unless the key does not exist.
+func (f *fileMap[V]) delete(key string) error {
+	prev, ok := f.m[key]
+	if !ok {
+		return nil
+	}
+	delete(f.m, key)
+	if err := f.save(); err != nil {
+		f.m[key] = prev
+		return err
+	}
+	return nil
+}
+
+// save writes the map to a temporary file which then replaces the file, so
+// the file is never left partially written.
+func (f *fileMap[V]) save() error {
+	data, err := json.Marshal(f.m)
+	if err != nil {
+		return err
+	}
+	if f.seal != nil {
+		if data, err = f.seal(data); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -1,10 +1,17 @@
 module github.com/teamwork/nylas-go
 
-go 1.13
+go 1.24.0
 
 require (
-	github.com/google/go-cmp v0.4.0
+	github.com/google/go-cmp v0.6.0
 	github.com/google/go-querystring v1.0.0
+	golang.org/x/net v0.45.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
-	golang.org/x/sync v0.0.0-20181221193216-37e7f081c4d4
+	golang.org/x/sync v0.17.0
+)
+
+require (
+	cloud.google.com/go v0.34.0 // indirect
+	github.com/golang/protobuf v1.2.0 // indirect
+	google.golang.org/appengine v1.4.0 // indirect
 )
@@ -0,0 +1,746 @@
+package nylas
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalMaxLineLength is the maximum length in octets of an iCalendar content
+// line before it must be folded, excluding the line break.
+const icalMaxLineLength = 75
+
+// ICalendarOptions provides optional parameters to MarshalICalendar.
+type ICalendarOptions struct {
+	// Method is the iTIP method of the calendar, e.g. REQUEST when sending
+	// an invite by email. Omitted when empty.
+	Method string
+	// ProdID identifies the product which created the calendar, defaults to
+	// -//Teamwork//nylas-go//EN.
+	ProdID string
+	// DTStamp is the time the calendar was created, defaults to now.
+	DTStamp time.Time
+}
+
+// Participant status values mapped to iCalendar PARTSTAT values.
+var icalPartStats = map[string]string{
+	"yes":     "ACCEPTED",
+	"no":      "DECLINED",
+	"maybe":   "TENTATIVE",
+	"noreply": "NEEDS-ACTION",
+}
+
+var icalTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// MarshalICalendar returns the events encoded as an RFC 5545 iCalendar
+// object with a VEVENT for each event, suitable for uploading with UploadFile
+// and attaching to a draft as an invite.
+//
+// The event's ICalUID is used as the UID, falling back to its ID. Times with a
+// time zone are written with a TZID of the IANA time zone name along with a
+// VTIMEZONE describing its offsets during the years of the events, and the
+// following five years for recurring events. Overrides of recurring events,
+// which have MasterEventID and OriginalStartTime set, are written with a
+// RECURRENCE-ID.
+// See: https://tools.ietf.org/html/rfc5545
+func MarshalICalendar(events []Event, opts *ICalendarOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &ICalendarOptions{}
+	}
+	prodID := opts.ProdID
+	if prodID == "" {
+		prodID = "-//Teamwork//nylas-go//EN"
+	}
+	stamp := opts.DTStamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	var buf bytes.Buffer
+	w := icalWriter{w: &buf}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + icalText(prodID))
+	w.line("CALSCALE:GREGORIAN")
+	if opts.Method != "" {
+		w.line("METHOD:" + strings.ToUpper(opts.Method))
+	}
+	spans := make(icalZoneSpans)
+	for _, e := range events {
+		spans.addEvent(e)
+	}
+	w.timeZones(spans)
+	for _, e := range events {
+		if err := w.event(e, stamp); err != nil {
+			return nil, err
+		}
+	}
+	w.line("END:VCALENDAR")
+	return buf.Bytes(), nil
+}
+
+// icalWriter writes folded iCalendar content lines.
+type icalWriter struct {
+	w *bytes.Buffer
+}
+
+func (w icalWriter) event(e Event, stamp time.Time) error {
+	uid := e.ICalUID
+	if uid == "" {
+		uid = e.ID
+	}
+	if uid == "" {
+		return errors.New("event has neither an ical uid nor an id")
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + icalText(uid))
+	w.line("DTSTAMP:" + stamp.UTC().Format(icalUTCDateTimeFormat))
+
+	var startTZ *TimeZone
+	dateOnly := false
+	switch when := e.When.(type) {
+	case *EventTime:
+		startTZ = when.Timezone
+		w.line(icalDateTime("DTSTART", when.Time, startTZ))
+	case *EventTimespan:
+		startTZ = when.StartTimezone
+		w.line(icalDateTime("DTSTART", when.StartTime, startTZ))
+		w.line(icalDateTime("DTEND", when.EndTime, when.EndTimezone))
+	case *EventDate:
+		dateOnly = true
+		w.line(icalDate("DTSTART", when.Date))
+		w.line(icalDate("DTEND", when.Date.AddDate(0, 0, 1)))
+	case *EventDatespan:
+		// Nylas date spans include their end date while DTEND excludes it.
+		dateOnly = true
+		w.line(icalDate("DTSTART", when.StartDate))
+		w.line(icalDate("DTEND", when.EndDate.AddDate(0, 0, 1)))
+	default:
+		return fmt.Errorf("event %s has no when", uid)
+	}
+
+	if e.MasterEventID != "" && !e.OriginalStartTime.IsZero() {
+		if dateOnly {
+			w.line(icalDate("RECURRENCE-ID", e.OriginalStartTime))
+		} else {
+			w.line(icalDateTime("RECURRENCE-ID", e.OriginalStartTime, startTZ))
+		}
+	}
+	for _, rule := range e.Recurrence.RRule {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if !strings.Contains(rule, ":") {
+			rule = "RRULE:" + rule
+		}
+		w.line(rule)
+	}
+
+	if e.Title != "" {
+		w.line("SUMMARY:" + icalText(e.Title))
+	}
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + icalText(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION:" + icalText(e.Location))
+	}
+	switch e.Status {
+	case EventStatusConfirmed, EventStatusTentative, EventStatusCancelled:
+		w.line("STATUS:" + strings.ToUpper(e.Status))
+	}
+	if e.Busy {
+		w.line("TRANSP:OPAQUE")
+	} else {
+		w.line("TRANSP:TRANSPARENT")
+	}
+
+	if e.Owner != "" {
+		if addr, err := mail.ParseAddress(e.Owner); err == nil {
+			w.line("ORGANIZER" + icalCommonName(addr.Name) + ":mailto:" + addr.Address)
+		}
+	}
+	for _, p := range e.Participants {
+		if p.Email == "" {
+			continue
+		}
+		partStat, ok := icalPartStats[p.Status]
+		if !ok {
+			partStat = "NEEDS-ACTION"
+		}
+		line := "ATTENDEE" + icalCommonName(p.Name) + ";PARTSTAT=" + partStat
+		if partStat == "NEEDS-ACTION" {
+			line += ";RSVP=TRUE"
+		}
+		w.line(line + ":mailto:" + p.Email)
+	}
+
+	w.line("END:VEVENT")
+	return nil
+}
+
+// line writes the content line folded to icalMaxLineLength octets, without
+// splitting multi-byte characters.
+func (w icalWriter) line(s string) {
+	max := icalMaxLineLength
+	for len(s) > max {
+		i := max
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		w.w.WriteString(s[:i])
+		w.w.WriteString("\r\n ")
+		s = s[i:]
+		// Continuation lines start with a space which counts to the limit.
+		max = icalMaxLineLength - 1
+	}
+	w.w.WriteString(s)
+	w.w.WriteString("\r\n")
+}
+
+// icalText escapes s as an iCalendar TEXT value.
+func icalText(s string) string {
+	return icalTextEscaper.Replace(s)
+}
+
+// icalParam returns s as an iCalendar parameter value, quoting it when it
+// contains separators. Parameter values cannot contain double quotes or line
+// breaks so they are removed.
+func icalParam(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s)
+	if strings.ContainsAny(s, ";:,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+func icalCommonName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return ";CN=" + icalParam(name)
+}
+
+// icalDateTime returns a date-time property in the time zone, or in UTC when
+// it has none.
+func icalDateTime(name string, t time.Time, tz *TimeZone) string {
+	if tz == nil || tz.Location == nil || tz.Location == time.UTC {
+		return name + ":" + t.UTC().Format(icalUTCDateTimeFormat)
+	}
+	return name + ";TZID=" + icalParam(tz.Location.String()) + ":" +
+		t.In(tz.Location).Format(icalDateTimeFormat)
+}
+
+func icalDate(name string, t time.Time) string {
+	return name + ";VALUE=DATE:" + t.Format(icalDateFormat)
+}
+
+// icalProperty is a parsed iCalendar content line.
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+	// raw is the unfolded content line.
+	raw string
+}
+
+// ICalendarEventError is a VEVENT which ParseICalendar could not parse.
+type ICalendarEventError struct {
+	// Line of the BEGIN:VEVENT.
+	Line int
+	// UID of the event, empty if it has none.
+	UID string
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ICalendarEventError) Error() string {
+	if e.UID == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: event %s: %v", e.Line, e.UID, e.Err)
+}
+
+// Unwrap returns the reason the event could not be parsed.
+func (e *ICalendarEventError) Unwrap() error {
+	return e.Err
+}
+
+// SkippedEventsError is returned by ParseICalendar, along with the events
+// which could be parsed, when some of the VEVENTs could not be.
+type SkippedEventsError struct {
+	Events []*ICalendarEventError
+}
+
+// Error implements the error interface.
+func (e *SkippedEventsError) Error() string {
+	msgs := make([]string, len(e.Events))
+	for i, err := range e.Events {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("skipped %d events: %s", len(e.Events), strings.Join(msgs, "; "))
+}
+
+// ParseICalendar parses the VEVENTs of an RFC 5545 iCalendar object into
+// requests for the CreateEvent method. The CalendarID of each request is left
+// empty for the caller to set.
+//
+// Events with a DTSTART date become EventDate or, when lasting more than a
+// day, EventDatespan. Events with a DTSTART date-time become EventTime or,
+// when they have a DTEND or DURATION, EventTimespan. Overrides of recurring
+// events, which have a RECURRENCE-ID, and cancelled events are skipped.
+//
+// TZID parameters may be IANA or Windows time zone names, as exported by
+// Outlook. Times in other time zones are converted to UTC using the VTIMEZONE
+// of the TZID, and floating times are read as UTC. Events which cannot be
+// parsed, such as those in a time zone which is neither known nor defined,
+// are skipped and returned in a *SkippedEventsError along with the other
+// events.
+// See: https://tools.ietf.org/html/rfc5545
+func ParseICalendar(r io.Reader) ([]EventRequest, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+	components, err := parseICalComponents(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := icalZones{defined: map[string]*icalTimeZone{}, errs: map[string]error{}}
+	var events []*icalComponent
+	for _, c := range components {
+		switch c.name {
+		case "VTIMEZONE":
+			tzid, tz, err := newICalTimeZone(c)
+			if err != nil {
+				zones.errs[tzid] = err
+				continue
+			}
+			zones.defined[tzid] = tz
+		case "VEVENT":
+			events = append(events, c)
+		}
+	}
+
+	var (
+		reqs    []EventRequest
+		skipped []*ICalendarEventError
+	)
+	for _, c := range events {
+		req, ok, err := newICalEventRequest(c.props, zones)
+		if err != nil {
+			skipped = append(skipped, &ICalendarEventError{Line: c.line, UID: c.prop("UID"), Err: err})
+			continue
+		}
+		if ok {
+			reqs = append(reqs, req)
+		}
+	}
+	if len(skipped) > 0 {
+		return reqs, &SkippedEventsError{Events: skipped}
+	}
+	return reqs, nil
+}
+
+// icalComponent is a parsed iCalendar component such as a VEVENT.
+type icalComponent struct {
+	name     string
+	props    []icalProperty
+	children []*icalComponent
+	// line of the BEGIN of the component.
+	line int
+}
+
+// prop returns the value of the first property with the name.
+func (c *icalComponent) prop(name string) string {
+	for _, p := range c.props {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// parseICalComponents parses the content lines into the components of the
+// calendar, those of a VCALENDAR are returned in its place.
+func parseICalComponents(lines []string) ([]*icalComponent, error) {
+	var (
+		roots []*icalComponent
+		stack []*icalComponent
+	)
+	for n, line := range lines {
+		prop, err := parseICalProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			c := &icalComponent{name: strings.ToUpper(prop.value), line: n + 1}
+			if len(stack) == 0 {
+				roots = append(roots, c)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || !strings.EqualFold(prop.value, stack[len(stack)-1].name) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", n+1, prop.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			// Properties outside of any component are ignored.
+			if len(stack) > 0 {
+				c := stack[len(stack)-1]
+				c.props = append(c.props, prop)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated %s", stack[len(stack)-1].name)
+	}
+
+	var components []*icalComponent
+	for _, c := range roots {
+		if c.name == "VCALENDAR" {
+			components = append(components, c.children...)
+		} else {
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// unfoldICalLines reads the content lines of r, joining folded lines.
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	br := bufio.NewReader(r)
+	for {
+		s, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		s = strings.TrimRight(s, "\r\n")
+		switch {
+		case s == "":
+		case (s[0] == ' ' || s[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += s[1:]
+		default:
+			lines = append(lines, s)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+	}
+}
+
+// parseICalProperty parses a content line of the form
+// name *(";" param) ":" value.
+func parseICalProperty(line string) (icalProperty, error) {
+	prop := icalProperty{raw: line}
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return icalProperty{}, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.name = strings.ToUpper(line[:i])
+	rest := line[i:]
+
+	for rest[0] == ';' {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return icalProperty{}, fmt.Errorf("invalid parameter in %q", line)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		// Values are separated by commas and may be quoted, only the
+		// first is kept.
+		var value string
+		first := true
+		for {
+			var v string
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.IndexByte(rest[1:], '"')
+				if end < 0 {
+					return icalProperty{}, fmt.Errorf("unterminated quote in %q", line)
+				}
+				v, rest = rest[1:end+1], rest[end+2:]
+			} else {
+				end := strings.IndexAny(rest, ";:,")
+				if end < 0 {
+					return icalProperty{}, fmt.Errorf("missing value in %q", line)
+				}
+				v, rest = rest[:end], rest[end:]
+			}
+			if first {
+				value, first = v, false
+			}
+			if !strings.HasPrefix(rest, ",") {
+				break
+			}
+			rest = rest[1:]
+		}
+		if prop.params == nil {
+			prop.params = make(map[string]string)
+		}
+		prop.params[key] = value
+
+		if rest == "" {
+			return icalProperty{}, fmt.Errorf("missing value in %q", line)
+		}
+	}
+	if rest[0] != ':' {
+		return icalProperty{}, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.value = rest[1:]
+	return prop, nil
+}
+
+// unescapeICalText reverses icalText.
+func unescapeICalText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseICalPropertyTime parses a DTSTART or DTEND property, times without a
+// TZID or UTC designator are read as UTC. Times in a time zone only defined
+// by a VTIMEZONE are converted to UTC and returned without a TimeZone.
+func parseICalPropertyTime(
+	prop icalProperty, zones icalZones,
+) (t time.Time, tz *TimeZone, date bool, err error) {
+	loc := time.UTC
+	var vtz *icalTimeZone
+	if tzid := prop.params["TZID"]; tzid != "" {
+		loc, vtz, err = zones.resolve(tzid)
+		if err != nil {
+			return time.Time{}, nil, false, fmt.Errorf("%s: %w", prop.name, err)
+		}
+		if vtz != nil {
+			loc = time.UTC
+		} else {
+			tz = &TimeZone{Location: loc}
+		}
+	}
+	t, date, err = parseICalTime(prop.value, loc)
+	if err != nil {
+		return time.Time{}, nil, false, fmt.Errorf("%s: %w", prop.name, err)
+	}
+	if strings.EqualFold(prop.params["VALUE"], "DATE") && !date {
+		return time.Time{}, nil, false, fmt.Errorf("%s: expected date: %s", prop.name, prop.value)
+	}
+	if date {
+		return civilDate(t.Date()), nil, true, nil
+	}
+	if vtz != nil && !strings.HasSuffix(prop.value, "Z") {
+		t = t.Add(-time.Duration(vtz.offset(t)) * time.Second)
+	}
+	return t, tz, false, nil
+}
+
+// parseICalDuration parses an RFC 5545 DURATION value into nominal days and
+// an exact duration.
+// See: https://tools.ietf.org/html/rfc5545#section-3.3.6
+func parseICalDuration(s string) (days int, d time.Duration, err error) {
+	v := strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(v, "P") || len(v) < 3 {
+		return 0, 0, fmt.Errorf("invalid duration %s", s)
+	}
+	v = v[1:]
+	inTime := false
+	for v != "" {
+		if v[0] == 'T' {
+			inTime = true
+			v = v[1:]
+			continue
+		}
+		i := 0
+		for i < len(v) && v[i] >= '0' && v[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(v) {
+			return 0, 0, fmt.Errorf("invalid duration %s", s)
+		}
+		n, err := strconv.Atoi(v[:i])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %s", s)
+		}
+		switch unit := v[i]; {
+		case unit == 'W' && !inTime:
+			days += 7 * n
+		case unit == 'D' && !inTime:
+			days += n
+		case unit == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case unit == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case unit == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, 0, fmt.Errorf("invalid duration %s", s)
+		}
+		v = v[i+1:]
+	}
+	return days, d, nil
+}
+
+// newICalEventRequest returns the request for the properties of a VEVENT, ok
+// is false when the event should be skipped.
+func newICalEventRequest(
+	props []icalProperty, zones icalZones,
+) (req EventRequest, ok bool, err error) {
+	var (
+		start, end       time.Time
+		startTZ, endTZ   *TimeZone
+		startDate        bool
+		hasStart, hasEnd bool
+		rrule            []string
+	)
+	busy := true
+	for _, prop := range props {
+		switch prop.name {
+		case "RECURRENCE-ID":
+			return EventRequest{}, false, nil
+		case "STATUS":
+			if strings.EqualFold(prop.value, "CANCELLED") {
+				return EventRequest{}, false, nil
+			}
+		case "SUMMARY":
+			req.Title = unescapeICalText(prop.value)
+		case "DESCRIPTION":
+			req.Description = unescapeICalText(prop.value)
+		case "LOCATION":
+			req.Location = unescapeICalText(prop.value)
+		case "TRANSP":
+			busy = !strings.EqualFold(prop.value, "TRANSPARENT")
+		case "DTSTART":
+			start, startTZ, startDate, err = parseICalPropertyTime(prop, zones)
+			if err != nil {
+				return EventRequest{}, false, err
+			}
+			hasStart = true
+		case "DTEND":
+			end, endTZ, _, err = parseICalPropertyTime(prop, zones)
+			if err != nil {
+				return EventRequest{}, false, err
+			}
+			hasEnd = true
+		case "RRULE", "EXDATE", "RDATE":
+			rrule = append(rrule, prop.raw)
+		case "ATTENDEE":
+			req.Participants = append(req.Participants, icalParticipant(prop))
+		}
+	}
+	if !hasStart {
+		return EventRequest{}, false, errors.New("VEVENT has no DTSTART")
+	}
+
+	// DURATION depends on DTSTART so is applied after every property is
+	// read.
+	for _, prop := range props {
+		if prop.name != "DURATION" {
+			continue
+		}
+		if hasEnd {
+			return EventRequest{}, false, errors.New("both DTEND and DURATION set")
+		}
+		days, d, err := parseICalDuration(prop.value)
+		if err != nil {
+			return EventRequest{}, false, err
+		}
+		if startDate && d != 0 {
+			return EventRequest{}, false, fmt.Errorf("duration %s of all day event has a time", prop.value)
+		}
+		end, endTZ, hasEnd = start.AddDate(0, 0, days).Add(d), startTZ, true
+	}
+
+	switch {
+	case startDate:
+		// DTEND is exclusive while Nylas date spans include their end date.
+		last := start
+		if hasEnd {
+			last = civilDate(end.Date()).AddDate(0, 0, -1)
+		}
+		if last.After(start) {
+			req.When = &EventDatespan{StartDate: start, EndDate: last}
+		} else {
+			req.When = &EventDate{Date: start}
+		}
+	case hasEnd:
+		if endTZ == nil {
+			endTZ = startTZ
+		}
+		req.When = &EventTimespan{
+			StartTime:     start,
+			EndTime:       end,
+			StartTimezone: startTZ,
+			EndTimezone:   endTZ,
+		}
+	default:
+		req.When = &EventTime{Time: start, Timezone: startTZ}
+	}
+
+	req.Busy = &busy
+	if len(rrule) > 0 {
+		req.Recurrence = &EventRecurrence{RRule: rrule, Timezone: startTZ}
+	}
+	return req, true, nil
+}
+
+func icalParticipant(prop icalProperty) EventParticipant {
+	email := prop.value
+	if len(email) > len("mailto:") && strings.EqualFold(email[:len("mailto:")], "mailto:") {
+		email = email[len("mailto:"):]
+	}
+	status := "noreply"
+	for s, partStat := range icalPartStats {
+		if strings.EqualFold(prop.params["PARTSTAT"], partStat) {
+			status = s
+		}
+	}
+	return EventParticipant{
+		Name:   prop.params["CN"],
+		Email:  email,
+		Status: status,
+	}
+}
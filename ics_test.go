@@ -0,0 +1,461 @@
+package nylas
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshalICalendar(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	events := []Event{
+		{
+			ID:          "event1",
+			ICalUID:     "uid1@example.com",
+			Title:       "Planning, Q3",
+			Description: "Agenda:\n1. Budget; 2. Hiring",
+			Location:    "Room 1",
+			Status:      EventStatusConfirmed,
+			Busy:        true,
+			Owner:       "Ada Lovelace <ada@example.com>",
+			When: &EventTimespan{
+				StartTime:     time.Date(2020, 3, 2, 9, 0, 0, 0, ny),
+				EndTime:       time.Date(2020, 3, 2, 10, 0, 0, 0, ny),
+				StartTimezone: &TimeZone{Location: ny},
+				EndTimezone:   &TimeZone{Location: ny},
+			},
+			Recurrence: EventRecurrence{
+				RRule:    []string{"RRULE:FREQ=WEEKLY;BYDAY=MO", "EXDATE;TZID=America/New_York:20200309T090000"},
+				Timezone: &TimeZone{Location: ny},
+			},
+			Participants: []EventParticipant{
+				{Name: "Lovelace, Ada", Email: "ada@example.com", Status: "yes"},
+				{Email: "bob@example.com", Status: "noreply"},
+				{Name: "Carol", Email: "carol@example.com", Status: "maybe"},
+			},
+		},
+		{
+			ID:   "event2",
+			When: &EventDatespan{StartDate: civilDate(2020, 3, 6), EndDate: civilDate(2020, 3, 8)},
+		},
+	}
+
+	got, err := MarshalICalendar(events, &ICalendarOptions{
+		Method:  "request",
+		DTStamp: time.Date(2020, 2, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Teamwork//nylas-go//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:REQUEST",
+		"BEGIN:VEVENT",
+		"UID:uid1@example.com",
+		"DTSTAMP:20200201T120000Z",
+		"DTSTART;TZID=America/New_York:20200302T090000",
+		"DTEND;TZID=America/New_York:20200302T100000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"EXDATE;TZID=America/New_York:20200309T090000",
+		`SUMMARY:Planning\, Q3`,
+		`DESCRIPTION:Agenda:\n1. Budget\; 2. Hiring`,
+		"LOCATION:Room 1",
+		"STATUS:CONFIRMED",
+		"TRANSP:OPAQUE",
+		"ORGANIZER;CN=Ada Lovelace:mailto:ada@example.com",
+		`ATTENDEE;CN="Lovelace, Ada";PARTSTAT=ACCEPTED:mailto:ada@example.com`,
+		"ATTENDEE;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:bob@example.com",
+		"ATTENDEE;CN=Carol;PARTSTAT=TENTATIVE:mailto:carol@example.com",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:event2",
+		"DTSTAMP:20200201T120000Z",
+		"DTSTART;VALUE=DATE:20200306",
+		"DTEND;VALUE=DATE:20200309",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	// The VTIMEZONE is covered by TestMarshalICalendarTimeZone.
+	if diff := cmp.Diff(withoutICalComponent(string(got), "VTIMEZONE"), want); diff != "" {
+		t.Errorf("MarshalICalendar: (-got +want):\n%s", diff)
+	}
+}
+
+func TestMarshalICalendarTimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	got, err := MarshalICalendar([]Event{{
+		ID:   "event",
+		When: &EventTime{Time: time.Date(2020, 3, 2, 9, 0, 0, 0, ny), Timezone: &TimeZone{Location: ny}},
+	}}, &ICalendarOptions{DTStamp: time.Date(2020, 2, 1, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Teamwork//nylas-go//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VTIMEZONE",
+		"TZID:America/New_York",
+		"BEGIN:STANDARD",
+		"DTSTART:20200101T000000",
+		"TZOFFSETFROM:-0500",
+		"TZOFFSETTO:-0500",
+		"TZNAME:EST",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:20200308T020000",
+		"TZOFFSETFROM:-0500",
+		"TZOFFSETTO:-0400",
+		"TZNAME:EDT",
+		"END:DAYLIGHT",
+		"BEGIN:STANDARD",
+		"DTSTART:20201101T020000",
+		"TZOFFSETFROM:-0400",
+		"TZOFFSETTO:-0500",
+		"TZNAME:EST",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:event",
+		"DTSTAMP:20200201T120000Z",
+		"DTSTART;TZID=America/New_York:20200302T090000",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("MarshalICalendar: (-got +want):\n%s", diff)
+	}
+
+	// The VTIMEZONE is enough to read the times back without the IANA name.
+	ics := strings.ReplaceAll(string(got), "America/New_York", "Custom Zone")
+	reqs, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if when, ok := reqs[0].When.(*EventTime); !ok || !when.Time.Equal(time.Date(2020, 3, 2, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected when: %#v", reqs[0].When)
+	}
+}
+
+// withoutICalComponent returns the iCalendar object with the components of
+// the name removed.
+func withoutICalComponent(ics, name string) string {
+	var lines []string
+	inside := false
+	for _, line := range strings.SplitAfter(ics, "\r\n") {
+		switch {
+		case line == "BEGIN:"+name+"\r\n":
+			inside = true
+		case line == "END:"+name+"\r\n":
+			inside = false
+		case !inside:
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func TestMarshalICalendarFolding(t *testing.T) {
+	title := strings.Repeat("é", 100)
+	got, err := MarshalICalendar([]Event{{
+		ID:    "event",
+		Title: title,
+		When:  &EventTime{Time: time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, line := range strings.Split(string(got), "\r\n") {
+		if len(line) > icalMaxLineLength {
+			t.Errorf("line longer than %d octets: %q", icalMaxLineLength, line)
+		}
+	}
+	lines, err := unfoldICalLines(strings.NewReader(string(got)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsString(lines, "SUMMARY:"+title) {
+		t.Errorf("folded summary not restored: %q", lines)
+	}
+}
+
+func TestMarshalICalendarNoWhen(t *testing.T) {
+	if _, err := MarshalICalendar([]Event{{ID: "event"}}, nil); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := MarshalICalendar([]Event{{When: &EventDate{}}}, nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestParseICalendar(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	nyTZ := &TimeZone{Location: ny}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//EN",
+		"BEGIN:VTIMEZONE",
+		"TZID:America/New_York",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:uid1@example.com",
+		"DTSTART;TZID=America/New_York:20200302T090000",
+		"DURATION:PT1H30M",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"EXDATE;TZID=America/New_York:20200309T090000",
+		`SUMMARY:Planning\, Q3`,
+		`DESCRIPTION:Agenda:\n1. Budget\; 2. `,
+		" Hiring",
+		`ATTENDEE;CN="Lovelace, Ada";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:MAILTO:`,
+		"\tada@example.com",
+		"ATTENDEE;PARTSTAT=DELEGATED:mailto:bob@example.com",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:uid1@example.com",
+		"RECURRENCE-ID;TZID=America/New_York:20200316T090000",
+		"DTSTART;TZID=America/New_York:20200316T110000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:uid2@example.com",
+		"DTSTART;VALUE=DATE:20200306",
+		"DTEND;VALUE=DATE:20200309",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:uid3@example.com",
+		"DTSTART;VALUE=DATE:20200310",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:uid4@example.com",
+		"DTSTART:20200311T140000Z",
+		"LOCATION:Room 1",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:uid5@example.com",
+		"DTSTART:20200311T140000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\n")
+
+	got, err := ParseICalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	busy, free := true, false
+	want := []EventRequest{
+		{
+			Title:       "Planning, Q3",
+			Description: "Agenda:\n1. Budget; 2. Hiring",
+			When: &EventTimespan{
+				StartTime:     time.Date(2020, 3, 2, 9, 0, 0, 0, ny),
+				EndTime:       time.Date(2020, 3, 2, 10, 30, 0, 0, ny),
+				StartTimezone: nyTZ,
+				EndTimezone:   nyTZ,
+			},
+			Participants: []EventParticipant{
+				{Name: "Lovelace, Ada", Email: "ada@example.com", Status: "yes"},
+				{Email: "bob@example.com", Status: "noreply"},
+			},
+			Busy: &busy,
+			Recurrence: &EventRecurrence{
+				RRule: []string{
+					"RRULE:FREQ=WEEKLY;BYDAY=MO",
+					"EXDATE;TZID=America/New_York:20200309T090000",
+				},
+				Timezone: nyTZ,
+			},
+		},
+		{
+			When: &EventDatespan{StartDate: civilDate(2020, 3, 6), EndDate: civilDate(2020, 3, 8)},
+			Busy: &free,
+		},
+		{
+			When: &EventDate{Date: civilDate(2020, 3, 10)},
+			Busy: &busy,
+		},
+		{
+			Location: "Room 1",
+			When:     &EventTime{Time: time.Date(2020, 3, 11, 14, 0, 0, 0, time.UTC)},
+			Busy:     &busy,
+		},
+	}
+	if diff := cmp.Diff(got, want, cmp.Comparer(compareTimeZones)); diff != "" {
+		t.Errorf("ParseICalendar: (-got +want):\n%s", diff)
+	}
+}
+
+func TestParseICalendarTimeZones(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:Custom Europe",
+		"BEGIN:STANDARD",
+		"DTSTART:16011028T030000",
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:16010325T020000",
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:outlook",
+		"DTSTART;TZID=Eastern Standard Time:20200302T090000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:summer",
+		"DTSTART;TZID=Custom Europe:20200329T090000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:winter",
+		"DTSTART;TZID=Custom Europe:20200328T090000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:unknown",
+		"DTSTART;TZID=Mars Standard Time:20200302T090000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	got, err := ParseICalendar(strings.NewReader(ics))
+	var skipped *SkippedEventsError
+	if !errors.As(err, &skipped) || len(skipped.Events) != 1 || skipped.Events[0].UID != "unknown" {
+		t.Fatalf("expected unknown event to be skipped, got: %v", err)
+	}
+
+	want := []EventRequest{
+		{When: &EventTime{Time: time.Date(2020, 3, 2, 9, 0, 0, 0, ny), Timezone: &TimeZone{Location: ny}}},
+		{When: &EventTime{Time: time.Date(2020, 3, 29, 7, 0, 0, 0, time.UTC)}},
+		{When: &EventTime{Time: time.Date(2020, 3, 28, 8, 0, 0, 0, time.UTC)}},
+	}
+	busy := true
+	for i := range want {
+		want[i].Busy = &busy
+	}
+	if diff := cmp.Diff(got, want, cmp.Comparer(compareTimeZones)); diff != "" {
+		t.Errorf("ParseICalendar: (-got +want):\n%s", diff)
+	}
+}
+
+func TestParseICalendarRoundTrip(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	nyTZ := &TimeZone{Location: ny}
+
+	busy := true
+	want := []EventRequest{{
+		Title:       `Quarterly; review, "all hands" \ 🎉`,
+		Description: strings.Repeat("Long description. ", 20),
+		Location:    "Dublin",
+		When: &EventTimespan{
+			StartTime:     time.Date(2020, 11, 1, 1, 30, 0, 0, ny),
+			EndTime:       time.Date(2020, 11, 1, 3, 0, 0, 0, ny),
+			StartTimezone: nyTZ,
+			EndTimezone:   nyTZ,
+		},
+		Participants: []EventParticipant{
+			{Name: "Dorothy Vaughan", Email: "dorothy@example.com", Status: "no"},
+		},
+		Busy: &busy,
+		Recurrence: &EventRecurrence{
+			RRule:    []string{"RRULE:FREQ=MONTHLY;BYDAY=1SU;COUNT=3"},
+			Timezone: nyTZ,
+		},
+	}}
+
+	r := want[0]
+	ics, err := MarshalICalendar([]Event{{
+		ID:           "event",
+		Title:        r.Title,
+		Description:  r.Description,
+		Location:     r.Location,
+		When:         r.When,
+		Participants: r.Participants,
+		Busy:         *r.Busy,
+		Recurrence:   *r.Recurrence,
+	}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ParseICalendar(strings.NewReader(string(ics)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, want, cmp.Comparer(compareTimeZones)); diff != "" {
+		t.Errorf("ParseICalendar: (-got +want):\n%s", diff)
+	}
+}
+
+func TestParseICalendarInvalid(t *testing.T) {
+	event := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.Join(lines, "\r\n") +
+			"\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	}
+	tests := map[string]string{
+		"no dtstart":       event("SUMMARY:Lunch"),
+		"unknown tzid":     event("DTSTART;TZID=Mars Standard Time:20200302T090000"),
+		"bad dtstart":      event("DTSTART:yesterday"),
+		"date value":       event("DTSTART;VALUE=DATE:20200302T090000"),
+		"bad duration":     event("DTSTART:20200302T090000Z", "DURATION:1H"),
+		"end and duration": event("DTSTART:20200302T090000Z", "DTEND:20200302T100000Z", "DURATION:PT1H"),
+		"date duration":    event("DTSTART;VALUE=DATE:20200302", "DURATION:PT1H"),
+		"no colon":         event("DTSTART:20200302T090000Z", "SUMMARY"),
+		"unterminated":     "BEGIN:VEVENT\r\nDTSTART:20200302T090000Z\r\n",
+		"unclosed quote":   event(`ATTENDEE;CN="Ada:mailto:ada@example.com`),
+		"mismatched end":   "BEGIN:VEVENT\r\nEND:VTODO\r\n",
+	}
+
+	for desc, ics := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := ParseICalendar(strings.NewReader(ics)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
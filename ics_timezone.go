@@ -0,0 +1,449 @@
+package nylas
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icalRecurringZoneYears is the number of years after its start the VTIMEZONE
+// of a recurring event covers.
+const icalRecurringZoneYears = 5
+
+// windowsTimeZones maps the Windows time zone names used as TZIDs by Outlook
+// and Exchange to IANA time zone names, based on the CLDR windowsZones table.
+// See: https://github.com/unicode-org/cldr/blob/main/common/supplemental/windowsZones.xml
+var windowsTimeZones = map[string]string{
+	"Dateline Standard Time":          "Etc/GMT+12",
+	"UTC-11":                          "Etc/GMT+11",
+	"Hawaiian Standard Time":          "Pacific/Honolulu",
+	"Alaskan Standard Time":           "America/Anchorage",
+	"Pacific Standard Time (Mexico)":  "America/Tijuana",
+	"Pacific Standard Time":           "America/Los_Angeles",
+	"US Mountain Standard Time":       "America/Phoenix",
+	"Mountain Standard Time (Mexico)": "America/Mazatlan",
+	"Mountain Standard Time":          "America/Denver",
+	"Central America Standard Time":   "America/Guatemala",
+	"Central Standard Time":           "America/Chicago",
+	"Central Standard Time (Mexico)":  "America/Mexico_City",
+	"Canada Central Standard Time":    "America/Regina",
+	"SA Pacific Standard Time":        "America/Bogota",
+	"Eastern Standard Time (Mexico)":  "America/Cancun",
+	"Eastern Standard Time":           "America/New_York",
+	"US Eastern Standard Time":        "America/Indiana/Indianapolis",
+	"Cuba Standard Time":              "America/Havana",
+	"Venezuela Standard Time":         "America/Caracas",
+	"Paraguay Standard Time":          "America/Asuncion",
+	"Atlantic Standard Time":          "America/Halifax",
+	"Central Brazilian Standard Time": "America/Cuiaba",
+	"SA Western Standard Time":        "America/La_Paz",
+	"Pacific SA Standard Time":        "America/Santiago",
+	"Newfoundland Standard Time":      "America/St_Johns",
+	"E. South America Standard Time":  "America/Sao_Paulo",
+	"Argentina Standard Time":         "America/Argentina/Buenos_Aires",
+	"SA Eastern Standard Time":        "America/Cayenne",
+	"Greenland Standard Time":         "America/Nuuk",
+	"Montevideo Standard Time":        "America/Montevideo",
+	"UTC-02":                          "Etc/GMT+2",
+	"Azores Standard Time":            "Atlantic/Azores",
+	"Cape Verde Standard Time":        "Atlantic/Cape_Verde",
+	"UTC":                             "Etc/UTC",
+	"GMT Standard Time":               "Europe/London",
+	"Greenwich Standard Time":         "Atlantic/Reykjavik",
+	"Morocco Standard Time":           "Africa/Casablanca",
+	"W. Europe Standard Time":         "Europe/Berlin",
+	"Central Europe Standard Time":    "Europe/Budapest",
+	"Romance Standard Time":           "Europe/Paris",
+	"Central European Standard Time":  "Europe/Warsaw",
+	"W. Central Africa Standard Time": "Africa/Lagos",
+	"Namibia Standard Time":           "Africa/Windhoek",
+	"Jordan Standard Time":            "Asia/Amman",
+	"GTB Standard Time":               "Europe/Bucharest",
+	"Middle East Standard Time":       "Asia/Beirut",
+	"Egypt Standard Time":             "Africa/Cairo",
+	"Syria Standard Time":             "Asia/Damascus",
+	"South Africa Standard Time":      "Africa/Johannesburg",
+	"FLE Standard Time":               "Europe/Helsinki",
+	"Israel Standard Time":            "Asia/Jerusalem",
+	"Kaliningrad Standard Time":       "Europe/Kaliningrad",
+	"Libya Standard Time":             "Africa/Tripoli",
+	"Arabic Standard Time":            "Asia/Baghdad",
+	"Turkey Standard Time":            "Europe/Istanbul",
+	"Arab Standard Time":              "Asia/Riyadh",
+	"Russian Standard Time":           "Europe/Moscow",
+	"E. Africa Standard Time":         "Africa/Nairobi",
+	"Iran Standard Time":              "Asia/Tehran",
+	"Arabian Standard Time":           "Asia/Dubai",
+	"Azerbaijan Standard Time":        "Asia/Baku",
+	"Mauritius Standard Time":         "Indian/Mauritius",
+	"Georgian Standard Time":          "Asia/Tbilisi",
+	"Caucasus Standard Time":          "Asia/Yerevan",
+	"Afghanistan Standard Time":       "Asia/Kabul",
+	"West Asia Standard Time":         "Asia/Tashkent",
+	"Ekaterinburg Standard Time":      "Asia/Yekaterinburg",
+	"Pakistan Standard Time":          "Asia/Karachi",
+	"India Standard Time":             "Asia/Kolkata",
+	"Sri Lanka Standard Time":         "Asia/Colombo",
+	"Nepal Standard Time":             "Asia/Kathmandu",
+	"Central Asia Standard Time":      "Asia/Almaty",
+	"Bangladesh Standard Time":        "Asia/Dhaka",
+	"Myanmar Standard Time":           "Asia/Yangon",
+	"SE Asia Standard Time":           "Asia/Bangkok",
+	"North Asia Standard Time":        "Asia/Krasnoyarsk",
+	"China Standard Time":             "Asia/Shanghai",
+	"North Asia East Standard Time":   "Asia/Irkutsk",
+	"Singapore Standard Time":         "Asia/Singapore",
+	"W. Australia Standard Time":      "Australia/Perth",
+	"Taipei Standard Time":            "Asia/Taipei",
+	"Ulaanbaatar Standard Time":       "Asia/Ulaanbaatar",
+	"Tokyo Standard Time":             "Asia/Tokyo",
+	"Korea Standard Time":             "Asia/Seoul",
+	"Yakutsk Standard Time":           "Asia/Yakutsk",
+	"Cen. Australia Standard Time":    "Australia/Adelaide",
+	"AUS Central Standard Time":       "Australia/Darwin",
+	"E. Australia Standard Time":      "Australia/Brisbane",
+	"AUS Eastern Standard Time":       "Australia/Sydney",
+	"West Pacific Standard Time":      "Pacific/Port_Moresby",
+	"Tasmania Standard Time":          "Australia/Hobart",
+	"Vladivostok Standard Time":       "Asia/Vladivostok",
+	"UTC+12":                          "Etc/GMT-12",
+	"New Zealand Standard Time":       "Pacific/Auckland",
+	"Fiji Standard Time":              "Pacific/Fiji",
+	"Tonga Standard Time":             "Pacific/Tongatapu",
+	"Samoa Standard Time":             "Pacific/Apia",
+}
+
+// icalZones are the time zones a calendar's TZID parameters refer to.
+type icalZones struct {
+	// defined are the VTIMEZONE components of the calendar by TZID, nil
+	// when the component could not be parsed.
+	defined map[string]*icalTimeZone
+	errs    map[string]error
+}
+
+// resolve returns the location of an IANA or Windows time zone name, or the
+// VTIMEZONE defined for the TZID when the name is not known. Exactly one of
+// loc and vtz is non-nil unless err is set.
+func (z icalZones) resolve(tzid string) (loc *time.Location, vtz *icalTimeZone, err error) {
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc, nil, nil
+	}
+	if name, ok := windowsTimeZones[tzid]; ok {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc, nil, nil
+		}
+	}
+	if err := z.errs[tzid]; err != nil {
+		return nil, nil, fmt.Errorf("VTIMEZONE %s: %w", tzid, err)
+	}
+	if vtz := z.defined[tzid]; vtz != nil {
+		return nil, vtz, nil
+	}
+	return nil, nil, fmt.Errorf("unknown time zone %s", tzid)
+}
+
+// icalTimeZone is a parsed VTIMEZONE component.
+// See: https://tools.ietf.org/html/rfc5545#section-3.6.5
+type icalTimeZone struct {
+	observances []icalObservance
+}
+
+// icalObservance is a STANDARD or DAYLIGHT sub-component of a VTIMEZONE.
+// Onsets are local times in the offset in effect before them, kept in UTC so
+// they compare as wall clock times.
+type icalObservance struct {
+	start      time.Time
+	offsetFrom int
+	offsetTo   int
+	// rule is the RRULE of the observance, nil when it only has a DTSTART
+	// and RDATEs.
+	rule   *rrule
+	rdates []time.Time
+}
+
+func newICalTimeZone(c *icalComponent) (tzid string, tz *icalTimeZone, err error) {
+	for _, prop := range c.props {
+		if prop.name == "TZID" {
+			tzid = prop.value
+		}
+	}
+	if tzid == "" {
+		return "", nil, errors.New("VTIMEZONE has no TZID")
+	}
+
+	tz = &icalTimeZone{}
+	for _, sub := range c.children {
+		if sub.name != "STANDARD" && sub.name != "DAYLIGHT" {
+			continue
+		}
+		o, err := newICalObservance(sub)
+		if err != nil {
+			return tzid, nil, fmt.Errorf("%s: %w", sub.name, err)
+		}
+		tz.observances = append(tz.observances, o)
+	}
+	if len(tz.observances) == 0 {
+		return tzid, nil, errors.New("no STANDARD or DAYLIGHT")
+	}
+	return tzid, tz, nil
+}
+
+func newICalObservance(c *icalComponent) (o icalObservance, err error) {
+	var hasStart, hasFrom, hasTo bool
+	for _, prop := range c.props {
+		switch prop.name {
+		case "DTSTART":
+			o.start, _, err = parseICalTime(prop.value, time.UTC)
+			hasStart = true
+		case "TZOFFSETFROM":
+			o.offsetFrom, err = parseICalUTCOffset(prop.value)
+			hasFrom = true
+		case "TZOFFSETTO":
+			o.offsetTo, err = parseICalUTCOffset(prop.value)
+			hasTo = true
+		case "RRULE":
+			o.rule, err = parseRRule(prop.value, time.UTC)
+			if err == nil && !isICalZoneRule(o.rule) {
+				err = fmt.Errorf("unsupported rule %s", prop.value)
+			}
+		case "RDATE":
+			for _, v := range strings.Split(prop.value, ",") {
+				var t time.Time
+				t, _, err = parseICalTime(v, time.UTC)
+				if err != nil {
+					break
+				}
+				o.rdates = append(o.rdates, t)
+			}
+		}
+		if err != nil {
+			return icalObservance{}, fmt.Errorf("%s: %w", prop.name, err)
+		}
+	}
+	if !hasStart || !hasFrom || !hasTo {
+		return icalObservance{}, errors.New("DTSTART, TZOFFSETFROM and TZOFFSETTO are required")
+	}
+	return o, nil
+}
+
+// isICalZoneRule reports whether the rule is a yearly rule on the nth weekday
+// of a month, such as FREQ=YEARLY;BYMONTH=3;BYDAY=2SU, which is the form
+// VTIMEZONE components use.
+func isICalZoneRule(r *rrule) bool {
+	return r.freq == "YEARLY" && r.interval == 1 && r.count == 0 &&
+		len(r.byMonth) == 1 && len(r.byDay) == 1 && r.byDay[0].n != 0 &&
+		len(r.byMonthDay) == 0 && len(r.bySetPos) == 0
+}
+
+// onsets returns the onsets of the observance in the year and the one before.
+func (o icalObservance) onsets(year int) []time.Time {
+	onsets := append([]time.Time{o.start}, o.rdates...)
+	if o.rule == nil {
+		return onsets
+	}
+	month := time.Month(o.rule.byMonth[0])
+	day := o.rule.byDay[0]
+	for y := year - 1; y <= year; y++ {
+		var d time.Time
+		if day.n > 0 {
+			d = civilDate(y, month, 1)
+			d = d.AddDate(0, 0, (int(day.day)-int(d.Weekday())+7)%7+7*(day.n-1))
+		} else {
+			d = civilDate(y, month, daysIn(y, month))
+			d = d.AddDate(0, 0, -((int(d.Weekday())-int(day.day)+7)%7)+7*(day.n+1))
+		}
+		if d.Month() != month {
+			continue
+		}
+		onset := d.Add(o.start.Sub(civilDate(o.start.Date())))
+		if onset.Before(o.start) {
+			continue
+		}
+		// UNTIL is usually in UTC while onsets are local times.
+		if !o.rule.until.IsZero() && onset.Add(-time.Duration(o.offsetFrom)*time.Second).After(o.rule.until) {
+			continue
+		}
+		onsets = append(onsets, onset)
+	}
+	return onsets
+}
+
+// offset returns the UTC offset in seconds of a local time, given as a time in
+// UTC with the same wall clock.
+func (tz *icalTimeZone) offset(local time.Time) int {
+	var (
+		latest time.Time
+		found  bool
+		offset int
+	)
+	for _, o := range tz.observances {
+		for _, onset := range o.onsets(local.Year()) {
+			if onset.After(local) || (found && !onset.After(latest)) {
+				continue
+			}
+			latest, found, offset = onset, true, o.offsetTo
+		}
+	}
+	if found {
+		return offset
+	}
+
+	// Times before the first onset use the offset it changes from.
+	first := tz.observances[0]
+	for _, o := range tz.observances[1:] {
+		if o.start.Before(first.start) {
+			first = o
+		}
+	}
+	return first.offsetFrom
+}
+
+// parseICalUTCOffset parses a UTC-OFFSET value such as -0500 into seconds.
+// See: https://tools.ietf.org/html/rfc5545#section-3.3.14
+func parseICalUTCOffset(s string) (int, error) {
+	if (len(s) != 5 && len(s) != 7) || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid utc offset %s", s)
+	}
+	var secs int
+	for i, mul := range []int{3600, 60, 1} {
+		if 1+2*i >= len(s) {
+			break
+		}
+		n, err := strconv.Atoi(s[1+2*i : 3+2*i])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid utc offset %s", s)
+		}
+		secs += n * mul
+	}
+	if s[0] == '-' {
+		secs = -secs
+	}
+	return secs, nil
+}
+
+// icalUTCOffset formats an offset in seconds as a UTC-OFFSET value.
+func icalUTCOffset(secs int) string {
+	sign := "+"
+	if secs < 0 {
+		sign, secs = "-", -secs
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, secs/3600, secs/60%60)
+	if secs%60 != 0 {
+		s += fmt.Sprintf("%02d", secs%60)
+	}
+	return s
+}
+
+// icalZoneSpan is the period the VTIMEZONE of a time zone written by
+// MarshalICalendar covers.
+type icalZoneSpan struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// icalZoneSpans are the time zones used by events keyed by TZID.
+type icalZoneSpans map[string]*icalZoneSpan
+
+func (s icalZoneSpans) add(tz *TimeZone, t time.Time, recurs bool) {
+	if tz == nil || tz.Location == nil || tz.Location == time.UTC {
+		return
+	}
+	to := t
+	if recurs {
+		to = t.AddDate(icalRecurringZoneYears, 0, 0)
+	}
+	span, ok := s[tz.Location.String()]
+	if !ok {
+		s[tz.Location.String()] = &icalZoneSpan{loc: tz.Location, from: t, to: to}
+		return
+	}
+	if t.Before(span.from) {
+		span.from = t
+	}
+	if to.After(span.to) {
+		span.to = to
+	}
+}
+
+// addEvent adds the time zones of the event's times and of the TZID
+// parameters of its recurrence rules.
+func (s icalZoneSpans) addEvent(e Event) {
+	recurs := len(e.Recurrence.RRule) > 0
+	var start time.Time
+	var startTZ *TimeZone
+	switch when := e.When.(type) {
+	case *EventTime:
+		start, startTZ = when.Time, when.Timezone
+	case *EventTimespan:
+		start, startTZ = when.StartTime, when.StartTimezone
+		s.add(when.EndTimezone, when.EndTime, recurs)
+	default:
+		return
+	}
+	s.add(startTZ, start, recurs)
+	if e.MasterEventID != "" && !e.OriginalStartTime.IsZero() {
+		s.add(startTZ, e.OriginalStartTime, false)
+	}
+	for _, rule := range e.Recurrence.RRule {
+		prop, err := parseICalProperty(strings.TrimSpace(rule))
+		if err != nil || prop.params["TZID"] == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(prop.params["TZID"]); err == nil {
+			s.add(&TimeZone{Location: loc}, start, recurs)
+		}
+	}
+}
+
+// timeZones writes a VTIMEZONE for each of the time zones, with an
+// observance for every offset change from the start of the first year of the
+// span to the end of its last year.
+func (w icalWriter) timeZones(spans icalZoneSpans) {
+	tzids := make([]string, 0, len(spans))
+	for tzid := range spans {
+		tzids = append(tzids, tzid)
+	}
+	sort.Strings(tzids)
+
+	for _, tzid := range tzids {
+		span := spans[tzid]
+		t := time.Date(span.from.In(span.loc).Year(), 1, 1, 0, 0, 0, 0, span.loc)
+		to := time.Date(span.to.In(span.loc).Year()+1, 1, 1, 0, 0, 0, 0, span.loc)
+
+		w.line("BEGIN:VTIMEZONE")
+		w.line("TZID:" + icalText(tzid))
+		_, offset := t.Zone()
+		w.observance(t, offset)
+		for {
+			_, end := t.ZoneBounds()
+			if end.IsZero() || !end.Before(to) {
+				break
+			}
+			w.observance(end, offset)
+			t = end.In(span.loc)
+			_, offset = t.Zone()
+		}
+		w.line("END:VTIMEZONE")
+	}
+}
+
+// observance writes the STANDARD or DAYLIGHT observance starting at t, where
+// offsetFrom is the offset in effect before t.
+func (w icalWriter) observance(t time.Time, offsetFrom int) {
+	name, offset := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + t.In(time.FixedZone("", offsetFrom)).Format(icalDateTimeFormat))
+	w.line("TZOFFSETFROM:" + icalUTCOffset(offsetFrom))
+	w.line("TZOFFSETTO:" + icalUTCOffset(offset))
+	if name != "" {
+		w.line("TZNAME:" + icalText(name))
+	}
+	w.line("END:" + kind)
+}
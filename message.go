@@ -2,9 +2,11 @@ package nylas
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -74,11 +76,11 @@ type MessagesOptions struct {
 	// Return messages belonging to a specific thread
 	ThreadID string `url:"thread_id,omitempty"`
 	Filename string `url:"filename,omitempty"`
-	// Return messages received before this Unix-based timestamp.
-	ReceivedBefore int64 `url:"received_before,omitempty"`
-	// Return messages received after this Unix-based timestamp.
-	ReceivedAfter int64 `url:"received_after,omitempty"`
-	HasAttachment *bool `url:"has_attachment,omitempty"`
+	// Return messages received before this time, sent as a Unix timestamp.
+	ReceivedBefore time.Time `url:"received_before,unix,omitempty"`
+	// Return messages received after this time, sent as a Unix timestamp.
+	ReceivedAfter time.Time `url:"received_after,unix,omitempty"`
+	HasAttachment *bool     `url:"has_attachment,omitempty"`
 }
 
 // Messages returns messages which match the filter specified by parameters.
@@ -143,6 +145,23 @@ func (c *Client) Message(ctx context.Context, id string, expanded bool) (Message
 // RawMessage returns the raw message in RFC-2822 format.
 // See: https://docs.nylas.com/reference#raw-message-contents
 func (c *Client) RawMessage(ctx context.Context, id string) ([]byte, error) {
+	body, err := c.RawMessageReader(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() // nolint: errcheck
+
+	return ioutil.ReadAll(body)
+}
+
+// RawMessageReader returns the raw message in RFC-2822 format as a stream,
+// avoiding buffering large messages in memory.
+//
+// If the returned error is nil, you are expected to read the io.ReadCloser to
+// EOF and close.
+//
+// See: https://docs.nylas.com/reference#raw-message-contents
+func (c *Client) RawMessageReader(ctx context.Context, id string) (io.ReadCloser, error) {
 	req, err := c.newUserRequest(ctx, http.MethodGet, "/messages/"+id, nil)
 	if err != nil {
 		return nil, err
@@ -154,13 +173,12 @@ func (c *Client) RawMessage(ctx context.Context, id string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close() // nolint: errcheck
 
 	if resp.StatusCode >= 299 {
-		return nil, NewError(resp)
+		defer resp.Body.Close() // nolint: errcheck
+		return nil, c.responseError(resp)
 	}
-
-	return ioutil.ReadAll(resp.Body)
+	return resp.Body, nil
 }
 
 // UpdateMessageRequest contains the request parameters required to update a
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -51,8 +52,8 @@ func TestMessages(t *testing.T) {
 		In:             "in",
 		Limit:          1,
 		Offset:         2,
-		ReceivedAfter:  6,
-		ReceivedBefore: 5,
+		ReceivedAfter:  time.Unix(6, 0),
+		ReceivedBefore: time.Unix(5, 0),
 		Starred:        Bool(true),
 		Subject:        "subject",
 		ThreadID:       "threadid",
@@ -248,6 +249,37 @@ Received: by 2002:ab3:5e90:0:0:0:0:0 with SMTP id k16csp2294558TLC;
 	}
 }
 
+func TestRawMessageReader(t *testing.T) {
+	accessToken := "accessToken"
+	id := "br57kcekhf1hsjq04y8aonkit"
+	want := []byte("Subject: hi\r\n\r\nbody")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/messages/"+id)
+
+		if v := r.Header.Get("Accept"); v != "message/rfc822" {
+			t.Errorf("missing/incorrect accept header: %v", v)
+		}
+		_, _ = w.Write(want)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	body, err := client.RawMessageReader(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	got, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Message: (-got +want):\n%s", diff)
+	}
+}
+
 func TestUpdateMessage(t *testing.T) {
 	accessToken := "accessToken"
 	wantQuery := url.Values{}
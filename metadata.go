@@ -0,0 +1,36 @@
+package nylas
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits the API enforces on the metadata which can be stored on an object.
+const (
+	MaxMetadataPairs       = 50
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 500
+)
+
+// validateMetadata returns an error if the metadata exceeds the limits
+// enforced by the API, so requests fail before being sent.
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataPairs {
+		return fmt.Errorf("metadata has %d pairs, at most %d are allowed",
+			len(metadata), MaxMetadataPairs)
+	}
+	for k, v := range metadata {
+		if k == "" {
+			return fmt.Errorf("metadata key must not be empty")
+		}
+		if n := utf8.RuneCountInString(k); n > MaxMetadataKeyLength {
+			return fmt.Errorf("metadata key %q is %d characters, at most %d are allowed",
+				k, n, MaxMetadataKeyLength)
+		}
+		if n := utf8.RuneCountInString(v); n > MaxMetadataValueLength {
+			return fmt.Errorf("metadata value of key %q is %d characters, at most %d are allowed",
+				k, n, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package nylas
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tooMany := make(map[string]string, MaxMetadataPairs+1)
+	for i := 0; i <= MaxMetadataPairs; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+	tests := map[string]struct {
+		metadata map[string]string
+		wantErr  bool
+	}{
+		"nil":   {nil, false},
+		"valid": {map[string]string{"external_id": "123", "empty": ""}, false},
+		"limits": {map[string]string{
+			strings.Repeat("é", MaxMetadataKeyLength): strings.Repeat("é", MaxMetadataValueLength),
+		}, false},
+		"too many pairs": {tooMany, true},
+		"empty key":      {map[string]string{"": "value"}, true},
+		"long key":       {map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): ""}, true},
+		"long value":     {map[string]string{"key": strings.Repeat("v", MaxMetadataValueLength+1)}, true},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			err := validateMetadata(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMetadata: got error %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package nylas
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// maxMIMEDepth bounds how deeply multipart bodies may be nested.
+const maxMIMEDepth = 32
+
+// MessageProtection describes the cryptographic protection detected in a
+// message.
+type MessageProtection struct {
+	Signed    bool
+	Encrypted bool
+}
+
+// MessagePart is a MIME part of a message other than its text and HTML
+// bodies, i.e. an attachment or inline part.
+type MessagePart struct {
+	// Header of the part with encoded-words decoded.
+	Header textproto.MIMEHeader
+	// ContentType is the media type without parameters, e.g. image/png.
+	ContentType string
+	Filename    string
+	// ContentID without angle brackets.
+	ContentID string
+	// Disposition is inline, attachment or empty when not given.
+	Disposition string
+	// Size of the decoded content in bytes.
+	Size int64
+
+	content []byte
+}
+
+// Open returns a reader of the part's decoded content. Parts returned by
+// ParseMessageStream have no content as it is passed to the callback instead.
+func (p *MessagePart) Open() io.Reader {
+	return bytes.NewReader(p.content)
+}
+
+// ParsedMessage is an RFC 822 message parsed into its headers, bodies and
+// parts, see ParseMessage.
+type ParsedMessage struct {
+	// Header contains every header with encoded-words decoded.
+	Header textproto.MIMEHeader
+
+	Subject    string
+	From       []Participant
+	To         []Participant
+	CC         []Participant
+	BCC        []Participant
+	ReplyTo    []Participant
+	Date       time.Time
+	MessageID  string
+	InReplyTo  string
+	References []string
+
+	// Text and HTML bodies converted to UTF-8, multiple bodies of the same
+	// type are joined with a newline.
+	Text string
+	HTML string
+
+	// Inline parts keyed by their Content-ID without angle brackets, as
+	// referenced by cid: URLs in the HTML body.
+	Inline map[string]*MessagePart
+	// Attachments are parts which are not bodies or inline parts.
+	Attachments []*MessagePart
+
+	// SMIME and PGP protection detected from multipart/signed,
+	// multipart/encrypted and application/pkcs7-mime parts or inline PGP
+	// armor in the text body.
+	SMIME MessageProtection
+	PGP   MessageProtection
+}
+
+// ParseMessageOptions provides optional parameters to ParseMessage and
+// ParseMessageStream.
+type ParseMessageOptions struct {
+	// CharsetReader returns a reader converting input from charset to
+	// UTF-8, e.g. charset.NewReaderLabel from golang.org/x/net/html/charset.
+	// It is used for charsets other than UTF-8, US-ASCII, ISO-8859-1,
+	// Windows-1252 and UTF-16, and text in other charsets is left
+	// unconverted when it is nil.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+}
+
+// ParseMessage parses a raw RFC 822 message, as returned by RawMessage, with
+// the content of every part held in memory.
+func ParseMessage(raw []byte, opts *ParseMessageOptions) (*ParsedMessage, error) {
+	return ParseMessageStream(bytes.NewReader(raw), opts, func(p *MessagePart, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		p.content = b
+		return err
+	})
+}
+
+// ParseMessageStream parses an RFC 822 message from r without holding the
+// attachments and inline parts in memory. Instead fn is called with each
+// part and a reader of its decoded content, which is only valid until fn
+// returns. Parsing stops at the first error returned by fn, which is then
+// returned. When fn is nil the content of the parts is discarded, which is
+// useful for reading the headers and sizes of the attachments.
+//
+// Text and HTML bodies are read into memory.
+func ParseMessageStream(
+	r io.Reader, opts *ParseMessageOptions, fn func(*MessagePart, io.Reader) error,
+) (*ParsedMessage, error) {
+	if opts == nil {
+		opts = &ParseMessageOptions{}
+	}
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &messageParser{opts: opts, fn: fn}
+	p.dec = &mime.WordDecoder{CharsetReader: p.charsetReader}
+
+	header := textproto.MIMEHeader(msg.Header)
+	pm := &ParsedMessage{
+		Header:     p.decodeHeader(header),
+		Subject:    p.decodeWords(header.Get("Subject")),
+		From:       p.addresses(header.Get("From")),
+		To:         p.addresses(header.Get("To")),
+		CC:         p.addresses(header.Get("Cc")),
+		BCC:        p.addresses(header.Get("Bcc")),
+		ReplyTo:    p.addresses(header.Get("Reply-To")),
+		MessageID:  trimAngles(header.Get("Message-Id")),
+		InReplyTo:  trimAngles(header.Get("In-Reply-To")),
+		References: messageIDs(header.Get("References")),
+		Inline:     make(map[string]*MessagePart),
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		pm.Date = date
+	}
+
+	if err := p.walk(pm, header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.Contains(pm.Text, "-----BEGIN PGP MESSAGE-----"):
+		pm.PGP.Encrypted = true
+	case strings.Contains(pm.Text, "-----BEGIN PGP SIGNED MESSAGE-----"):
+		pm.PGP.Signed = true
+	}
+	return pm, nil
+}
+
+type messageParser struct {
+	opts *ParseMessageOptions
+	fn   func(*MessagePart, io.Reader) error
+	dec  *mime.WordDecoder
+}
+
+func (p *messageParser) walk(pm *ParsedMessage, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxMIMEDepth {
+		return errors.New("message parts are nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Content without a valid type is plain text, see RFC 2045
+		// section 5.2.
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		protocol := strings.ToLower(params["protocol"])
+		switch mediaType {
+		case "multipart/signed":
+			pm.SMIME.Signed = pm.SMIME.Signed || strings.Contains(protocol, "pkcs7-signature")
+			pm.PGP.Signed = pm.PGP.Signed || protocol == "application/pgp-signature"
+		case "multipart/encrypted":
+			pm.PGP.Encrypted = pm.PGP.Encrypted || protocol == "application/pgp-encrypted"
+		}
+
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("%s part has no boundary", mediaType)
+		}
+		mr := multipart.NewReader(body, boundary)
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.walk(pm, part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch mediaType {
+	case "application/pkcs7-mime", "application/x-pkcs7-mime":
+		if strings.EqualFold(params["smime-type"], "signed-data") {
+			pm.SMIME.Signed = true
+		} else {
+			pm.SMIME.Encrypted = true
+		}
+	}
+
+	body = transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = p.decodeWords(filename)
+
+	if (mediaType == "text/plain" || mediaType == "text/html") &&
+		disposition != "attachment" && filename == "" {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		text := p.decodeCharset(params["charset"], b)
+		if mediaType == "text/plain" {
+			pm.Text = joinBody(pm.Text, text)
+		} else {
+			pm.HTML = joinBody(pm.HTML, text)
+		}
+		return nil
+	}
+
+	part := &MessagePart{
+		Header:      p.decodeHeader(header),
+		ContentType: mediaType,
+		Filename:    filename,
+		ContentID:   trimAngles(header.Get("Content-Id")),
+		Disposition: disposition,
+	}
+	cr := &countingReader{r: body}
+	if p.fn != nil {
+		if err := p.fn(part, cr); err != nil {
+			return err
+		}
+	}
+	// Drain what the callback did not read so Size is accurate and the
+	// next part can be read.
+	if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+		return err
+	}
+	part.Size = cr.n
+
+	if part.ContentID != "" && disposition != "attachment" {
+		pm.Inline[part.ContentID] = part
+	} else {
+		pm.Attachments = append(pm.Attachments, part)
+	}
+	return nil
+}
+
+func (p *messageParser) decodeHeader(header textproto.MIMEHeader) textproto.MIMEHeader {
+	decoded := make(textproto.MIMEHeader, len(header))
+	for k, vs := range header {
+		for _, v := range vs {
+			decoded[k] = append(decoded[k], p.decodeWords(v))
+		}
+	}
+	return decoded
+}
+
+// decodeWords decodes RFC 2047 encoded-words, returning s unchanged if they
+// cannot be decoded.
+func (p *messageParser) decodeWords(s string) string {
+	decoded, err := p.dec.DecodeHeader(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// addresses parses an address list header, returning nil if it is missing or
+// malformed.
+func (p *messageParser) addresses(s string) []Participant {
+	if s == "" {
+		return nil
+	}
+	parser := mail.AddressParser{WordDecoder: p.dec}
+	list, err := parser.ParseList(s)
+	if err != nil {
+		return nil
+	}
+	participants := make([]Participant, len(list))
+	for i, a := range list {
+		participants[i] = Participant{Name: a.Name, Email: a.Address}
+	}
+	return participants
+}
+
+// charsetReader converts charsets for mime.WordDecoder, which handles UTF-8,
+// US-ASCII and ISO-8859-1 itself.
+func (p *messageParser) charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	b, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := p.convertCharset(charset, b)
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+	return strings.NewReader(s), nil
+}
+
+// decodeCharset returns b converted from charset to UTF-8, or unconverted if
+// the charset is not supported.
+func (p *messageParser) decodeCharset(charset string, b []byte) string {
+	s, ok := p.convertCharset(charset, b)
+	if !ok {
+		return string(b)
+	}
+	return s
+}
+
+func (p *messageParser) convertCharset(charset string, b []byte) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return string(b), true
+	case "iso-8859-1", "iso8859-1", "latin1", "l1":
+		return decodeSingleByte(b, nil), true
+	case "windows-1252", "cp1252":
+		return decodeSingleByte(b, &windows1252), true
+	case "utf-16", "utf-16be", "utf-16le":
+		return decodeUTF16(strings.ToLower(charset), b), true
+	}
+
+	if p.opts.CharsetReader == nil {
+		return "", false
+	}
+	r, err := p.opts.CharsetReader(charset, bytes.NewReader(b))
+	if err != nil || r == nil {
+		return "", false
+	}
+	converted, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", false
+	}
+	return string(converted), true
+}
+
+// windows1252 maps bytes 0x80 to 0x9F, which differ from ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// decodeSingleByte decodes ISO-8859-1, with bytes 0x80 to 0x9F replaced by
+// high when given.
+func decodeSingleByte(b []byte, high *[32]rune) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		if high != nil && c >= 0x80 && c < 0xA0 {
+			sb.WriteRune(high[c-0x80])
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// decodeUTF16 decodes UTF-16 using the byte order mark if present, otherwise
+// the byte order of the charset name, defaulting to big endian.
+func decodeUTF16(charset string, b []byte) string {
+	littleEndian := charset == "utf-16le"
+	switch {
+	case len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF:
+		littleEndian, b = false, b[2:]
+	case len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE:
+		littleEndian, b = true, b[2:]
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		if littleEndian {
+			units[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+		} else {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// transferDecoder returns a reader decoding body from the
+// Content-Transfer-Encoding.
+func transferDecoder(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// base64Cleaner removes characters which are not part of the base64
+// alphabet, such as the whitespace used to wrap lines.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	for {
+		n, err := c.r.Read(p)
+		j := 0
+		for _, b := range p[:n] {
+			if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' ||
+				b == '+' || b == '/' || b == '=' {
+				p[j] = b
+				j++
+			}
+		}
+		if j > 0 || err != nil {
+			return j, err
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func joinBody(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
+
+func trimAngles(s string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "<"), ">")
+}
+
+// messageIDs returns the message IDs in a References or In-Reply-To header.
+func messageIDs(s string) []string {
+	var ids []string
+	for _, f := range strings.Fields(s) {
+		if id := trimAngles(f); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
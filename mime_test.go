@@ -0,0 +1,287 @@
+package nylas
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func crlf(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+var mixedMessage = crlf(
+	`From: =?UTF-8?B?SsO8cmdlbiBNw7xsbGVy?= <jurgen@example.com>`,
+	`To: "Doe, Jane" <jane@example.com>, bob@example.com`,
+	`Cc: =?ISO-8859-1?Q?Andr=E9?= <andre@example.com>`,
+	`Subject: =?windows-1252?Q?Caf=E9_=93menu=94?=`,
+	`Date: Mon, 02 Mar 2020 09:00:00 -0500`,
+	`Message-ID: <abc@example.com>`,
+	`In-Reply-To: <parent@example.com>`,
+	`References: <root@example.com> <parent@example.com>`,
+	`X-Custom: =?UTF-8?Q?caf=C3=A9?=`,
+	`MIME-Version: 1.0`,
+	`Content-Type: multipart/mixed; boundary="mixed"`,
+	``,
+	`--mixed`,
+	`Content-Type: multipart/related; boundary="related"`,
+	``,
+	`--related`,
+	`Content-Type: multipart/alternative; boundary="alt"`,
+	``,
+	`--alt`,
+	`Content-Type: text/plain; charset=ISO-8859-1`,
+	`Content-Transfer-Encoding: quoted-printable`,
+	``,
+	`Caf=E9 menu`,
+	`--alt`,
+	`Content-Type: text/html; charset=utf-8`,
+	`Content-Transfer-Encoding: base64`,
+	``,
+	`PHA+Q2Fmw6kgPGltZyBzcmM9ImNpZDpsb2dvQGV4YW1wbGUuY29tIj48L3A+`,
+	`--alt--`,
+	`--related`,
+	`Content-Type: image/png`,
+	`Content-ID: <logo@example.com>`,
+	`Content-Transfer-Encoding: base64`,
+	``,
+	`iVBORw0K`,
+	`GgoAAAAN`,
+	`--related--`,
+	`--mixed`,
+	`Content-Type: application/pdf; name="=?UTF-8?Q?r=C3=A9sum=C3=A9.pdf?="`,
+	`Content-Disposition: attachment`,
+	`Content-Transfer-Encoding: base64`,
+	``,
+	`JVBERi0xLjQK`,
+	`--mixed`,
+	`Content-Type: text/plain; charset=utf-8`,
+	`Content-Disposition: attachment; filename*=UTF-8''notes%20%C3%A9.txt`,
+	``,
+	`notes`,
+	`--mixed--`,
+	``,
+)
+
+func TestParseMessage(t *testing.T) {
+	got, err := ParseMessage(mixedMessage, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &ParsedMessage{
+		Subject:    "Café “menu”",
+		From:       []Participant{{Name: "Jürgen Müller", Email: "jurgen@example.com"}},
+		To:         []Participant{{Name: "Doe, Jane", Email: "jane@example.com"}, {Email: "bob@example.com"}},
+		CC:         []Participant{{Name: "André", Email: "andre@example.com"}},
+		Date:       time.Date(2020, 3, 2, 14, 0, 0, 0, time.UTC),
+		MessageID:  "abc@example.com",
+		InReplyTo:  "parent@example.com",
+		References: []string{"root@example.com", "parent@example.com"},
+		Text:       "Café menu",
+		HTML:       `<p>Café <img src="cid:logo@example.com"></p>`,
+		Inline: map[string]*MessagePart{
+			"logo@example.com": {
+				ContentType: "image/png",
+				ContentID:   "logo@example.com",
+				Size:        12,
+				content:     []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"),
+			},
+		},
+		Attachments: []*MessagePart{
+			{
+				ContentType: "application/pdf",
+				Filename:    "résumé.pdf",
+				Disposition: "attachment",
+				Size:        9,
+				content:     []byte("%PDF-1.4\n"),
+			},
+			{
+				ContentType: "text/plain",
+				Filename:    "notes é.txt",
+				Disposition: "attachment",
+				Size:        5,
+				content:     []byte("notes"),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(got, want,
+		cmp.AllowUnexported(MessagePart{}),
+		cmpopts.IgnoreFields(ParsedMessage{}, "Header"),
+		cmpopts.IgnoreFields(MessagePart{}, "Header"),
+		cmpopts.EquateApproxTime(0),
+	); diff != "" {
+		t.Errorf("ParseMessage: (-got +want):\n%s", diff)
+	}
+
+	if v := got.Header.Get("X-Custom"); v != "café" {
+		t.Errorf("X-Custom header: got %q; want %q", v, "café")
+	}
+	if v := got.Attachments[0].Header.Get("Content-Type"); v != `application/pdf; name="résumé.pdf"` {
+		t.Errorf("attachment Content-Type header: got %q", v)
+	}
+	b, err := ioutil.ReadAll(got.Attachments[0].Open())
+	if err != nil || string(b) != "%PDF-1.4\n" {
+		t.Errorf("Open: got %q, %v", b, err)
+	}
+}
+
+func TestParseMessageCharsets(t *testing.T) {
+	message := func(charset, encoding, body string) []byte {
+		return crlf(
+			`Content-Type: text/plain; charset=`+charset,
+			`Content-Transfer-Encoding: `+encoding,
+			``,
+			body,
+		)
+	}
+	upper := func(charset string, input io.Reader) (io.Reader, error) {
+		if charset != "x-upper" {
+			return nil, errors.New("unknown charset")
+		}
+		b, err := ioutil.ReadAll(input)
+		return bytes.NewReader(bytes.ToUpper(b)), err
+	}
+
+	tests := map[string]struct {
+		raw  []byte
+		opts *ParseMessageOptions
+		want string
+	}{
+		"windows-1252": {message("windows-1252", "quoted-printable", "=80 =93hi=94"), nil, "€ “hi”"},
+		"utf-16 bom":   {message("UTF-16", "base64", "//5oAGkA"), nil, "hi"},
+		"utf-16be":     {message("utf-16be", "base64", "AGgAaQ=="), nil, "hi"},
+		"unknown":      {message("x-upper", "7bit", "hi"), nil, "hi"},
+		"reader":       {message("x-upper", "7bit", "hi"), &ParseMessageOptions{CharsetReader: upper}, "HI"},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, err := ParseMessage(tt.raw, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Text != tt.want {
+				t.Errorf("Text: got %q; want %q", got.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMessageProtection(t *testing.T) {
+	tests := map[string]struct {
+		raw   []byte
+		smime MessageProtection
+		pgp   MessageProtection
+	}{
+		"smime signed": {
+			raw: crlf(
+				`Content-Type: multipart/signed; protocol="application/pkcs7-signature"; boundary=b`,
+				``, `--b`, `Content-Type: text/plain`, ``, `hi`,
+				`--b`, `Content-Type: application/pkcs7-signature; name=smime.p7s`, ``, `sig`, `--b--`,
+			),
+			smime: MessageProtection{Signed: true},
+		},
+		"smime encrypted": {
+			raw: crlf(
+				`Content-Type: application/pkcs7-mime; smime-type=enveloped-data; name=smime.p7m`,
+				`Content-Transfer-Encoding: base64`, ``, `aGk=`,
+			),
+			smime: MessageProtection{Encrypted: true},
+		},
+		"pgp encrypted": {
+			raw: crlf(
+				`Content-Type: multipart/encrypted; protocol="application/pgp-encrypted"; boundary=b`,
+				``, `--b`, `Content-Type: application/pgp-encrypted`, ``, `Version: 1`,
+				`--b`, `Content-Type: application/octet-stream`, ``, `-----BEGIN PGP MESSAGE-----`, `--b--`,
+			),
+			pgp: MessageProtection{Encrypted: true},
+		},
+		"inline pgp signed": {
+			raw:   crlf(``, `-----BEGIN PGP SIGNED MESSAGE-----`, `Hash: SHA256`, ``, `hi`),
+			pgp:   MessageProtection{Signed: true},
+			smime: MessageProtection{},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, err := ParseMessage(tt.raw, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.SMIME != tt.smime || got.PGP != tt.pgp {
+				t.Errorf("protection: got S/MIME %+v PGP %+v; want %+v %+v", got.SMIME, got.PGP, tt.smime, tt.pgp)
+			}
+		})
+	}
+}
+
+func TestParseMessageStream(t *testing.T) {
+	var parts []string
+	got, err := ParseMessageStream(bytes.NewReader(mixedMessage), nil, func(p *MessagePart, r io.Reader) error {
+		// Read only part of the content, the rest must be skipped.
+		b := make([]byte, 4)
+		n, _ := io.ReadFull(r, b)
+		parts = append(parts, p.ContentType+":"+string(b[:n]))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"image/png:\x89PNG", "application/pdf:%PDF", "text/plain:note"}
+	if diff := cmp.Diff(parts, want); diff != "" {
+		t.Errorf("parts: (-got +want):\n%s", diff)
+	}
+	if got.Attachments[0].Size != 9 || got.Text != "Café menu" {
+		t.Errorf("unexpected message: %+v", got)
+	}
+	if b, _ := ioutil.ReadAll(got.Attachments[0].Open()); len(b) != 0 {
+		t.Errorf("streamed part has content: %q", b)
+	}
+
+	wantErr := errors.New("stop")
+	_, err = ParseMessageStream(bytes.NewReader(mixedMessage), nil, func(*MessagePart, io.Reader) error {
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("got error %v; want %v", err, wantErr)
+	}
+
+	got, err = ParseMessageStream(bytes.NewReader(mixedMessage), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Attachments) == 0 || got.Attachments[0].Size != 9 {
+		t.Errorf("unexpected attachments without fn: %+v", got.Attachments)
+	}
+}
+
+func TestParseMessageInvalid(t *testing.T) {
+	deep := &bytes.Buffer{}
+	for i := 0; i <= maxMIMEDepth+1; i++ {
+		deep.WriteString("Content-Type: multipart/mixed; boundary=b\r\n\r\n--b\r\n")
+	}
+
+	tests := map[string][]byte{
+		"bad header":  []byte("Subject hi\r\n\r\n"),
+		"no boundary": crlf(`Content-Type: multipart/mixed`, ``, `body`),
+		"too deep":    deep.Bytes(),
+	}
+	for desc, raw := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := ParseMessage(raw, nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package nylas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Outbox job statuses.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// OutboxMessage is a message scheduled to be sent through the outbox.
+type OutboxMessage struct {
+	Message
+	// SendAt is the unix timestamp the message is scheduled to be sent at.
+	SendAt int64 `json:"send_at"`
+	// RetryLimitDatetime is the unix timestamp after which sending is no
+	// longer retried.
+	RetryLimitDatetime int64 `json:"retry_limit_datetime"`
+	// OriginalSendAt is the unix timestamp the message was first scheduled
+	// to be sent at.
+	OriginalSendAt int64 `json:"original_send_at"`
+}
+
+// OutboxJob is the status of a scheduled send.
+type OutboxJob struct {
+	JobStatusID  string        `json:"job_status_id"`
+	AccountID    string        `json:"account_id"`
+	Status       string        `json:"status"`
+	OriginalData OutboxMessage `json:"original_data"`
+}
+
+// OutboxRequest contains the request parameters required to schedule a
+// message to be sent.
+type OutboxRequest struct {
+	DraftRequest
+	// SendAt is when the message should be sent, it is sent immediately if
+	// zero.
+	SendAt time.Time
+	// RetryLimitDatetime is when to stop retrying if sending fails, which
+	// must be after SendAt.
+	RetryLimitDatetime time.Time
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r OutboxRequest) MarshalJSON() ([]byte, error) {
+	type draft DraftRequest
+	return json.Marshal(struct {
+		draft
+		SendAt             int64 `json:"send_at,omitempty"`
+		RetryLimitDatetime int64 `json:"retry_limit_datetime,omitempty"`
+	}{
+		draft:              draft(r.DraftRequest),
+		SendAt:             unixOrZero(r.SendAt),
+		RetryLimitDatetime: unixOrZero(r.RetryLimitDatetime),
+	})
+}
+
+// UpdateOutboxRequest contains the request parameters required to update a
+// scheduled message, fields are optional and will overwrite previous values
+// if given.
+type UpdateOutboxRequest struct {
+	Subject            *string
+	To                 *[]Participant
+	CC                 *[]Participant
+	BCC                *[]Participant
+	Body               *string
+	SendAt             *time.Time
+	RetryLimitDatetime *time.Time
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r UpdateOutboxRequest) MarshalJSON() ([]byte, error) {
+	unix := func(t *time.Time) *int64 {
+		if t == nil {
+			return nil
+		}
+		v := t.Unix()
+		return &v
+	}
+	return json.Marshal(struct {
+		Subject            *string        `json:"subject,omitempty"`
+		To                 *[]Participant `json:"to,omitempty"`
+		CC                 *[]Participant `json:"cc,omitempty"`
+		BCC                *[]Participant `json:"bcc,omitempty"`
+		Body               *string        `json:"body,omitempty"`
+		SendAt             *int64         `json:"send_at,omitempty"`
+		RetryLimitDatetime *int64         `json:"retry_limit_datetime,omitempty"`
+	}{
+		Subject:            r.Subject,
+		To:                 r.To,
+		CC:                 r.CC,
+		BCC:                r.BCC,
+		Body:               r.Body,
+		SendAt:             unix(r.SendAt),
+		RetryLimitDatetime: unix(r.RetryLimitDatetime),
+	})
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// OutboxJobs returns the messages scheduled in the outbox.
+// See: https://developer.nylas.com/docs/api/#get/v2/outbox
+func (c *Client) OutboxJobs(ctx context.Context) ([]OutboxJob, error) {
+	req, err := c.newUserRequest(ctx, http.MethodGet, "/v2/outbox", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []OutboxJob
+	return resp, c.do(req, &resp)
+}
+
+// CreateOutboxJob schedules a message to be sent.
+// See: https://developer.nylas.com/docs/api/#post/v2/outbox
+func (c *Client) CreateOutboxJob(ctx context.Context, outboxReq OutboxRequest) (OutboxJob, error) {
+	req, err := c.newUserRequest(ctx, http.MethodPost, "/v2/outbox", &outboxReq)
+	if err != nil {
+		return OutboxJob{}, err
+	}
+
+	var resp OutboxJob
+	return resp, c.do(req, &resp)
+}
+
+// UpdateOutboxJob updates a scheduled message identified by its job status ID.
+// See: https://developer.nylas.com/docs/api/#patch/v2/outbox/job_status_id
+func (c *Client) UpdateOutboxJob(
+	ctx context.Context, jobStatusID string, updateReq UpdateOutboxRequest,
+) (OutboxJob, error) {
+	req, err := c.newUserRequest(ctx, http.MethodPatch, "/v2/outbox/"+jobStatusID, &updateReq)
+	if err != nil {
+		return OutboxJob{}, err
+	}
+
+	var resp OutboxJob
+	return resp, c.do(req, &resp)
+}
+
+// CancelOutboxJob cancels a scheduled message identified by its job status ID.
+// See: https://developer.nylas.com/docs/api/#delete/v2/outbox/job_status_id
+func (c *Client) CancelOutboxJob(ctx context.Context, jobStatusID string) error {
+	req, err := c.newUserRequest(ctx, http.MethodDelete, "/v2/outbox/"+jobStatusID, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
@@ -0,0 +1,148 @@
+package nylas
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var outboxJobJSON = []byte(`{
+	"job_status_id": "job1",
+	"account_id": "acc1",
+	"status": "pending",
+	"original_data": {
+		"subject": "Later",
+		"to": [{"email": "to@example.org", "name": "To"}],
+		"body": "body",
+		"send_at": 1583157600,
+		"retry_limit_datetime": 1583161200,
+		"original_send_at": 1583157600
+	}
+}`)
+
+func wantOutboxJob() OutboxJob {
+	job := OutboxJob{
+		JobStatusID: "job1",
+		AccountID:   "acc1",
+		Status:      OutboxStatusPending,
+		OriginalData: OutboxMessage{
+			SendAt:             1583157600,
+			RetryLimitDatetime: 1583161200,
+			OriginalSendAt:     1583157600,
+		},
+	}
+	job.OriginalData.Subject = "Later"
+	job.OriginalData.To = []Participant{{Email: "to@example.org", Name: "To"}}
+	job.OriginalData.Body = "body"
+	return job
+}
+
+func TestOutboxJobs(t *testing.T) {
+	accessToken := "accessToken"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/v2/outbox")
+
+		_, _ = w.Write([]byte("["))
+		_, _ = w.Write(outboxJobJSON)
+		_, _ = w.Write([]byte("]"))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.OutboxJobs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(got, []OutboxJob{wantOutboxJob()}); diff != "" {
+		t.Errorf("OutboxJobs: (-got +want):\n%s", diff)
+	}
+}
+
+func TestCreateOutboxJob(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := []byte(`{"subject":"Later","from":null,"to":[{"email":"to@example.org","name":"To"}],"cc":null,"bcc":null,"reply_to":null,"body":"body","file_ids":null,"send_at":1583157600,"retry_limit_datetime":1583161200}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/v2/outbox")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+
+		if diff := cmp.Diff(string(body), string(wantBody)); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+		_, _ = w.Write(outboxJobJSON)
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.CreateOutboxJob(context.Background(), OutboxRequest{
+		DraftRequest: DraftRequest{
+			Subject: "Later",
+			To:      []Participant{{Email: "to@example.org", Name: "To"}},
+			Body:    "body",
+		},
+		SendAt:             time.Unix(1583157600, 0),
+		RetryLimitDatetime: time.Unix(1583161200, 0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(got, wantOutboxJob()); diff != "" {
+		t.Errorf("CreateOutboxJob: (-got +want):\n%s", diff)
+	}
+}
+
+func TestUpdateOutboxJob(t *testing.T) {
+	accessToken := "accessToken"
+	wantBody := []byte(`{"subject":"Sooner","send_at":1583154000}`)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPatch, "/v2/outbox/job1")
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %v", err)
+		}
+
+		if diff := cmp.Diff(string(body), string(wantBody)); diff != "" {
+			t.Errorf("req body: (-got +want):\n%s", diff)
+		}
+		_, _ = w.Write(outboxJobJSON)
+	}))
+	defer ts.Close()
+
+	sendAt := time.Unix(1583154000, 0)
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	_, err := client.UpdateOutboxJob(context.Background(), "job1", UpdateOutboxRequest{
+		Subject: String("Sooner"),
+		SendAt:  &sendAt,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCancelOutboxJob(t *testing.T) {
+	accessToken := "accessToken"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodDelete, "/v2/outbox/job1")
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	if err := client.CancelOutboxJob(context.Background(), "job1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,727 @@
+package nylas
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrencePeriods bounds the number of periods (days, weeks, months or
+// years) a single rule is expanded over, guarding against rules which can
+// never produce an occurrence.
+const maxRecurrencePeriods = 200000
+
+// iCalendar date and date-time formats, see:
+// https://tools.ietf.org/html/rfc5545#section-3.3.4
+// https://tools.ietf.org/html/rfc5545#section-3.3.5
+const (
+	icalDateFormat        = "20060102"
+	icalDateTimeFormat    = "20060102T150405"
+	icalUTCDateTimeFormat = "20060102T150405Z"
+)
+
+// weekdayNum is a BYDAY value, n is the optional ordinal e.g. -1 in -1FR.
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+// rrule is a parsed RFC 5545 recurrence rule.
+// See: https://tools.ietf.org/html/rfc5545#section-3.3.10
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	untilDate  bool
+	byMonth    []int
+	byMonthDay []int
+	byDay      []weekdayNum
+	bySetPos   []int
+	wkst       time.Weekday
+}
+
+// recurrenceDate is an EXDATE or RDATE value, date is true when it has no
+// time component.
+type recurrenceDate struct {
+	t    time.Time
+	date bool
+}
+
+// recurrence is the parsed content of EventRecurrence.RRule.
+type recurrence struct {
+	rules   []*rrule
+	exdates []recurrenceDate
+	rdates  []recurrenceDate
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRecurrence parses RRULE, EXDATE and RDATE lines, floating times are
+// read in loc.
+func parseRecurrence(lines []string, loc *time.Location) (recurrence, error) {
+	var rec recurrence
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		head, value := "RRULE", line
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			head, value = line[:i], line[i+1:]
+		}
+		parts := strings.Split(head, ";")
+		name := strings.ToUpper(parts[0])
+
+		switch name {
+		case "RRULE":
+			r, err := parseRRule(value, loc)
+			if err != nil {
+				return recurrence{}, fmt.Errorf("%s: %w", line, err)
+			}
+			rec.rules = append(rec.rules, r)
+		case "EXDATE", "RDATE":
+			dates, err := parseRecurrenceDates(parts[1:], value, loc)
+			if err != nil {
+				return recurrence{}, fmt.Errorf("%s: %w", line, err)
+			}
+			if name == "EXDATE" {
+				rec.exdates = append(rec.exdates, dates...)
+			} else {
+				rec.rdates = append(rec.rdates, dates...)
+			}
+		default:
+			return recurrence{}, fmt.Errorf("%s: unsupported recurrence property", line)
+		}
+	}
+	return rec, nil
+}
+
+func parseRecurrenceDates(params []string, value string, loc *time.Location) ([]recurrenceDate, error) {
+	dateOnly := false
+	for _, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToUpper(kv[0]) {
+		case "TZID":
+			tz, err := time.LoadLocation(kv[1])
+			if err != nil {
+				return nil, err
+			}
+			loc = tz
+		case "VALUE":
+			switch strings.ToUpper(kv[1]) {
+			case "DATE":
+				dateOnly = true
+			case "DATE-TIME":
+			default:
+				return nil, fmt.Errorf("unsupported value type %s", kv[1])
+			}
+		}
+	}
+
+	var dates []recurrenceDate
+	for _, v := range strings.Split(value, ",") {
+		t, date, err := parseICalTime(v, loc)
+		if err != nil {
+			return nil, err
+		}
+		if dateOnly && !date {
+			return nil, fmt.Errorf("expected date: %s", v)
+		}
+		dates = append(dates, recurrenceDate{t: t, date: date})
+	}
+	return dates, nil
+}
+
+// parseICalTime parses an iCalendar date or date-time, date is true when the
+// value has no time component. Floating times are read in loc.
+func parseICalTime(v string, loc *time.Location) (t time.Time, date bool, err error) {
+	switch {
+	case len(v) == len(icalDateFormat):
+		t, err = time.ParseInLocation(icalDateFormat, v, loc)
+		return t, true, err
+	case strings.HasSuffix(v, "Z"):
+		t, err = time.Parse(icalUTCDateTimeFormat, v)
+		return t, false, err
+	default:
+		t, err = time.ParseInLocation(icalDateTimeFormat, v, loc)
+		return t, false, err
+	}
+}
+
+func parseRRule(value string, loc *time.Location) (*rrule, error) {
+	r := &rrule{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		k, v := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+		switch k {
+		case "FREQ":
+			switch v {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.freq = v
+			default:
+				return nil, fmt.Errorf("unsupported frequency %s", v)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = errors.New("interval must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+			if err == nil && r.count < 1 {
+				err = errors.New("count must be positive")
+			}
+		case "UNTIL":
+			r.until, r.untilDate, err = parseICalTime(v, loc)
+		case "BYMONTH":
+			r.byMonth, err = parseInts(v, 1, 12, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(v, 1, 31, true)
+		case "BYSETPOS":
+			r.bySetPos, err = parseInts(v, 1, 366, true)
+		case "BYDAY":
+			r.byDay, err = parseWeekdayNums(v)
+		case "WKST":
+			day, ok := icalWeekdays[v]
+			if !ok {
+				err = fmt.Errorf("invalid weekday %s", v)
+			}
+			r.wkst = day
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	if r.freq == "" {
+		return nil, errors.New("missing FREQ")
+	}
+	return r, nil
+}
+
+// parseInts parses a comma separated list of integers within [min, max], or
+// [-max, -min] too when negative is true.
+func parseInts(v string, min, max int, negative bool) ([]int, error) {
+	var ints []int
+	for _, s := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+func parseWeekdayNums(v string) ([]weekdayNum, error) {
+	var days []weekdayNum
+	for _, s := range strings.Split(v, ",") {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid weekday %s", s)
+		}
+		day, ok := icalWeekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %s", s)
+		}
+		wn := weekdayNum{day: day}
+		if ord := s[:len(s)-2]; ord != "" {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid weekday %s", s)
+			}
+			wn.n = n
+		}
+		days = append(days, wn)
+	}
+	return days, nil
+}
+
+// civilDate is a date without a time or location, normalized in UTC so date
+// arithmetic is unaffected by DST.
+func civilDate(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(y int, m time.Month) int {
+	return civilDate(y, m+1, 0).Day()
+}
+
+// occurrences returns the start of every occurrence of the rule from dtstart,
+// which is always the first, up to and including windowEnd.
+func (r *rrule) occurrences(dtstart, windowEnd time.Time) []time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hh, mm, ss, 0, loc)
+	}
+	start := civilDate(y, m, d)
+
+	until := windowEnd
+	if !r.until.IsZero() {
+		u := r.until
+		if r.untilDate {
+			// A date only UNTIL includes occurrences on that day.
+			u = at(civilDate(u.Year(), u.Month(), u.Day()))
+		}
+		if u.Before(until) {
+			until = u
+		}
+	}
+
+	out := []time.Time{dtstart}
+	if r.count == 1 {
+		return out
+	}
+	for k := 0; k < maxRecurrencePeriods; k++ {
+		periodStart, days := r.period(start, k)
+		if at(periodStart).After(until) {
+			break
+		}
+		for _, day := range days {
+			t := at(day)
+			if !t.After(dtstart) {
+				continue
+			}
+			if t.After(until) {
+				return out
+			}
+			out = append(out, t)
+			if r.count > 0 && len(out) >= r.count {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// period returns the first day of the k-th period from start and the sorted
+// days within it matching the rule.
+func (r *rrule) period(start time.Time, k int) (time.Time, []time.Time) {
+	var periodStart time.Time
+	var days []time.Time
+	switch r.freq {
+	case "DAILY":
+		periodStart = start.AddDate(0, 0, k*r.interval)
+		if r.matchesMonth(periodStart) && r.matchesMonthDay(periodStart) && r.matchesWeekday(periodStart) {
+			days = []time.Time{periodStart}
+		}
+	case "WEEKLY":
+		offset := (int(start.Weekday()) - int(r.wkst) + 7) % 7
+		periodStart = start.AddDate(0, 0, k*7*r.interval-offset)
+		for i := 0; i < 7; i++ {
+			day := periodStart.AddDate(0, 0, i)
+			matches := day.Weekday() == start.Weekday()
+			if len(r.byDay) > 0 {
+				matches = r.matchesWeekday(day)
+			}
+			if matches && r.matchesMonth(day) {
+				days = append(days, day)
+			}
+		}
+	case "MONTHLY":
+		periodStart = civilDate(start.Year(), start.Month()+time.Month(k*r.interval), 1)
+		if r.matchesMonth(periodStart) {
+			days = r.monthDays(periodStart.Year(), periodStart.Month(), start.Day())
+		}
+	case "YEARLY":
+		periodStart = civilDate(start.Year()+k*r.interval, time.January, 1)
+		days = r.yearDays(periodStart.Year(), start)
+	}
+	return periodStart, r.setPos(days)
+}
+
+func (r *rrule) matchesMonth(day time.Time) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, m := range r.byMonth {
+		if time.Month(m) == day.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rrule) matchesMonthDay(day time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	n := daysIn(day.Year(), day.Month())
+	for _, md := range r.byMonthDay {
+		if md < 0 {
+			md = n + md + 1
+		}
+		if md == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether the day matches any BYDAY weekday, ignoring
+// ordinals.
+func (r *rrule) matchesWeekday(day time.Time) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wn := range r.byDay {
+		if wn.day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays returns the days of the month matching BYMONTHDAY and BYDAY, with
+// BYDAY ordinals counted within the month. dtDay is used when neither is set.
+func (r *rrule) monthDays(y int, m time.Month, dtDay int) []time.Time {
+	n := daysIn(y, m)
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		if dtDay > n {
+			return nil
+		}
+		return []time.Time{civilDate(y, m, dtDay)}
+	}
+
+	var days []time.Time
+	for d := 1; d <= n; d++ {
+		day := civilDate(y, m, d)
+		if !r.matchesMonthDay(day) {
+			continue
+		}
+		if len(r.byDay) > 0 && !matchesOrdinal(r.byDay, day, (d-1)/7+1, (n-d)/7+1) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// yearDays returns the days of the year matching the rule, with BYDAY
+// ordinals counted within the year unless BYMONTH is set.
+func (r *rrule) yearDays(y int, start time.Time) []time.Time {
+	if len(r.byMonth) > 0 {
+		var days []time.Time
+		for m := time.January; m <= time.December; m++ {
+			if r.matchesMonth(civilDate(y, m, 1)) {
+				days = append(days, r.monthDays(y, m, start.Day())...)
+			}
+		}
+		return days
+	}
+
+	switch {
+	case len(r.byDay) > 0:
+		n := civilDate(y+1, time.January, 1).Sub(civilDate(y, time.January, 1)).Hours() / 24
+		var days []time.Time
+		for i := 0; i < int(n); i++ {
+			day := civilDate(y, time.January, 1+i)
+			if r.matchesMonthDay(day) && matchesOrdinal(r.byDay, day, i/7+1, (int(n)-1-i)/7+1) {
+				days = append(days, day)
+			}
+		}
+		return days
+	case len(r.byMonthDay) > 0:
+		var days []time.Time
+		for m := time.January; m <= time.December; m++ {
+			days = append(days, r.monthDays(y, m, start.Day())...)
+		}
+		return days
+	default:
+		if start.Day() > daysIn(y, start.Month()) {
+			return nil // e.g. February 29th in a non leap year
+		}
+		return []time.Time{civilDate(y, start.Month(), start.Day())}
+	}
+}
+
+// matchesOrdinal reports whether the day matches any of the BYDAY values,
+// where nth and nthLast are the day's position among the same weekday
+// counting from the start and end of the period.
+func matchesOrdinal(byDay []weekdayNum, day time.Time, nth, nthLast int) bool {
+	for _, wn := range byDay {
+		if wn.day != day.Weekday() {
+			continue
+		}
+		if wn.n == 0 || wn.n == nth || -wn.n == nthLast {
+			return true
+		}
+	}
+	return false
+}
+
+// setPos filters the sorted days of a period with BYSETPOS.
+func (r *rrule) setPos(days []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var out []time.Time
+	for _, pos := range r.bySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) {
+			out = append(out, days[i])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupeTimes(out)
+}
+
+func dedupeTimes(ts []time.Time) []time.Time {
+	out := ts[:0]
+	for i, t := range ts {
+		if i == 0 || !t.Equal(ts[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// recurringWhen describes how to place a recurring event's when subobject at
+// each occurrence.
+type recurringWhen struct {
+	dtstart time.Time
+	date    bool
+	build   func(start time.Time) EventTimeSubobject
+}
+
+func newRecurringWhen(when EventTimeSubobject, loc *time.Location) (recurringWhen, error) {
+	switch w := when.(type) {
+	case *EventTimespan:
+		dur := w.EndTime.Sub(w.StartTime)
+		return recurringWhen{
+			dtstart: w.StartTime.In(loc),
+			build: func(t time.Time) EventTimeSubobject {
+				return &EventTimespan{
+					Object:        w.Object,
+					StartTime:     t,
+					EndTime:       t.Add(dur),
+					StartTimezone: w.StartTimezone,
+					EndTimezone:   w.EndTimezone,
+				}
+			},
+		}, nil
+	case *EventTime:
+		return recurringWhen{
+			dtstart: w.Time.In(loc),
+			build: func(t time.Time) EventTimeSubobject {
+				return &EventTime{Object: w.Object, Time: t, Timezone: w.Timezone}
+			},
+		}, nil
+	case *EventDate:
+		return recurringWhen{
+			dtstart: time.Date(w.Date.Year(), w.Date.Month(), w.Date.Day(), 0, 0, 0, 0, loc),
+			date:    true,
+			build: func(t time.Time) EventTimeSubobject {
+				return &EventDate{Object: w.Object, Date: civilDate(t.Date())}
+			},
+		}, nil
+	case *EventDatespan:
+		days := int(w.EndDate.Sub(w.StartDate).Hours() / 24)
+		return recurringWhen{
+			dtstart: time.Date(w.StartDate.Year(), w.StartDate.Month(), w.StartDate.Day(), 0, 0, 0, 0, loc),
+			date:    true,
+			build: func(t time.Time) EventTimeSubobject {
+				start := civilDate(t.Date())
+				return &EventDatespan{Object: w.Object, StartDate: start, EndDate: start.AddDate(0, 0, days)}
+			},
+		}, nil
+	}
+	return recurringWhen{}, errors.New("recurring event has no when")
+}
+
+// recurrenceLocation returns the time zone a master event recurs in.
+func recurrenceLocation(e Event) *time.Location {
+	if tz := e.Recurrence.Timezone; tz != nil && tz.Location != nil {
+		return tz.Location
+	}
+	return e.TimeZone(nil)
+}
+
+// ExpandEvent returns the occurrences of the recurring master event which
+// overlap the window [start, end).
+//
+// Occurrences are copies of the master with When moved to the occurrence,
+// MasterEventID set to the master's ID, OriginalStartTime set to the
+// occurrence's start and both ID and Recurrence cleared. The rule is
+// evaluated in the recurrence time zone, so occurrences keep their wall clock
+// time across DST transitions.
+//
+// Overrides are exceptions to the master, as returned by the Events method
+// with MasterEventID set, which replace the occurrence at their
+// OriginalStartTime. Cancelled overrides remove the occurrence.
+func ExpandEvent(master Event, overrides []Event, start, end time.Time) ([]Event, error) {
+	loc := recurrenceLocation(master)
+	rw, err := newRecurringWhen(master.When, loc)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := parseRecurrence(master.Recurrence.RRule, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := []time.Time{rw.dtstart}
+	for _, r := range rec.rules {
+		starts = append(starts, r.occurrences(rw.dtstart, end)...)
+	}
+	for _, rd := range rec.rdates {
+		t := rd.t.In(loc)
+		if rd.date {
+			h, m, s := rw.dtstart.Clock()
+			t = time.Date(t.Year(), t.Month(), t.Day(), h, m, s, 0, loc)
+		}
+		starts = append(starts, t)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	starts = dedupeTimes(starts)
+
+	overridden := make(map[string]bool, len(overrides))
+	key := func(t time.Time) string {
+		if rw.date {
+			return t.Format(icalDateFormat)
+		}
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	for _, o := range overrides {
+		t := o.OriginalStartTime
+		if rw.date {
+			t = t.UTC()
+		}
+		overridden[key(t)] = true
+	}
+
+	var events []Event
+	for _, t := range starts {
+		if isExcluded(rec.exdates, t, loc) || overridden[key(t)] {
+			continue
+		}
+		e := master
+		e.ID = ""
+		e.When = rw.build(t)
+		e.Recurrence = EventRecurrence{}
+		e.MasterEventID = master.ID
+		e.OriginalStartTime = t
+		if r, ok := eventRange(e.When, loc); ok && inWindow(r, start, end) {
+			events = append(events, e)
+		}
+	}
+	for _, o := range overrides {
+		if o.Status == EventStatusCancelled {
+			continue
+		}
+		if r, ok := eventRange(o.When, loc); ok && inWindow(r, start, end) {
+			events = append(events, o)
+		}
+	}
+
+	sortEvents(events, loc)
+	return events, nil
+}
+
+func isExcluded(exdates []recurrenceDate, t time.Time, loc *time.Location) bool {
+	for _, ex := range exdates {
+		if ex.date {
+			ey, em, ed := ex.t.Date()
+			ty, tm, td := t.In(loc).Date()
+			if ey == ty && em == tm && ed == td {
+				return true
+			}
+		} else if ex.t.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// inWindow reports whether r overlaps [start, end), an empty range overlaps
+// when its instant is within the window.
+func inWindow(r timeRange, start, end time.Time) bool {
+	if !r.end.After(r.start) {
+		return !r.start.Before(start) && r.start.Before(end)
+	}
+	return r.start.Before(end) && r.end.After(start)
+}
+
+func sortEvents(events []Event, loc *time.Location) {
+	sort.SliceStable(events, func(i, j int) bool {
+		ri, _ := eventRange(events[i].When, loc)
+		rj, _ := eventRange(events[j].When, loc)
+		return ri.start.Before(rj.start)
+	})
+}
+
+// ExpandRecurringEvents returns the events which overlap the window
+// [start, end), with recurring master events replaced by their occurrences
+// as returned by ExpandEvent.
+//
+// Overrides are matched to their master by MasterEventID, those whose master
+// is not among the events are treated as single events. The result is
+// sorted by start time, with all-day events placed in UTC.
+func ExpandRecurringEvents(events []Event, start, end time.Time) ([]Event, error) {
+	masters := make(map[string]bool)
+	for _, e := range events {
+		if len(e.Recurrence.RRule) > 0 {
+			masters[e.ID] = true
+		}
+	}
+	overrides := make(map[string][]Event)
+	for _, e := range events {
+		if e.MasterEventID != "" && masters[e.MasterEventID] {
+			overrides[e.MasterEventID] = append(overrides[e.MasterEventID], e)
+		}
+	}
+
+	var expanded []Event
+	for _, e := range events {
+		switch {
+		case e.MasterEventID != "" && masters[e.MasterEventID]:
+			// Included by ExpandEvent with its master.
+		case len(e.Recurrence.RRule) > 0:
+			occurrences, err := ExpandEvent(e, overrides[e.ID], start, end)
+			if err != nil {
+				return nil, fmt.Errorf("expand event %s: %w", e.ID, err)
+			}
+			expanded = append(expanded, occurrences...)
+		default:
+			if r, ok := eventRange(e.When, time.UTC); ok && inWindow(r, start, end) {
+				expanded = append(expanded, e)
+			}
+		}
+	}
+
+	sortEvents(expanded, time.UTC)
+	return expanded, nil
+}
@@ -0,0 +1,273 @@
+package nylas
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func eventStarts(t *testing.T, events []Event, loc *time.Location) []string {
+	t.Helper()
+	var starts []string
+	for _, e := range events {
+		r, ok := eventRange(e.When, loc)
+		if !ok {
+			t.Fatalf("event without when: %+v", e)
+		}
+		starts = append(starts, r.start.In(loc).Format("2006-01-02 15:04 MST"))
+	}
+	return starts
+}
+
+func TestExpandEvent(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	nyTZ := &TimeZone{Location: ny}
+	master := func(when EventTimeSubobject, rrule ...string) Event {
+		return Event{
+			ID:         "master",
+			Title:      "Standup",
+			When:       when,
+			Recurrence: EventRecurrence{RRule: rrule, Timezone: nyTZ},
+		}
+	}
+	timespan := &EventTimespan{
+		StartTime: time.Date(2020, 3, 2, 9, 0, 0, 0, ny),
+		EndTime:   time.Date(2020, 3, 2, 9, 30, 0, 0, ny),
+	}
+	windowStart := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		master    Event
+		overrides []Event
+		start     time.Time
+		end       time.Time
+		want      []string
+	}{
+		"weekly across DST": {
+			master: master(timespan, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-04 09:00 EST",
+				"2020-03-09 09:00 EDT",
+				"2020-03-11 09:00 EDT",
+			},
+		},
+		"daily interval until": {
+			master: master(timespan, "RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20200306T140000Z"),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-04 09:00 EST",
+				"2020-03-06 09:00 EST",
+			},
+		},
+		"monthly last friday": {
+			master: master(timespan, "RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-27 09:00 EDT",
+				"2020-04-24 09:00 EDT",
+			},
+		},
+		"monthly skips short months": {
+			master: master(&EventTimespan{
+				StartTime: time.Date(2020, 1, 31, 9, 0, 0, 0, ny),
+				EndTime:   time.Date(2020, 1, 31, 10, 0, 0, 0, ny),
+			}, "RRULE:FREQ=MONTHLY;COUNT=3"),
+			want: []string{
+				"2020-01-31 09:00 EST",
+				"2020-03-31 09:00 EDT",
+				"2020-05-31 09:00 EDT",
+			},
+		},
+		"monthly last weekday": {
+			master: master(timespan, "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3"),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-31 09:00 EDT",
+				"2020-04-30 09:00 EDT",
+			},
+		},
+		"yearly nth weekday": {
+			master: master(timespan, "RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH"),
+			start:  windowStart,
+			end:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-11-26 09:00 EST",
+				"2021-11-25 09:00 EST",
+				"2022-11-24 09:00 EST",
+			},
+		},
+		"exdate and rdate": {
+			master: master(timespan,
+				"RRULE:FREQ=WEEKLY;COUNT=3",
+				"EXDATE;TZID=America/New_York:20200309T090000",
+				"RDATE:20200320T130000Z",
+			),
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-16 09:00 EDT",
+				"2020-03-20 09:00 EDT",
+			},
+		},
+		"overrides": {
+			master: master(timespan, "RRULE:FREQ=DAILY;COUNT=3"),
+			overrides: []Event{
+				{
+					ID:                "moved",
+					MasterEventID:     "master",
+					OriginalStartTime: time.Date(2020, 3, 3, 9, 0, 0, 0, ny),
+					When: &EventTimespan{
+						StartTime: time.Date(2020, 3, 5, 11, 0, 0, 0, ny),
+						EndTime:   time.Date(2020, 3, 5, 12, 0, 0, 0, ny),
+					},
+				},
+				{
+					ID:                "cancelled",
+					MasterEventID:     "master",
+					Status:            EventStatusCancelled,
+					OriginalStartTime: time.Date(2020, 3, 4, 9, 0, 0, 0, ny),
+				},
+			},
+			want: []string{
+				"2020-03-02 09:00 EST",
+				"2020-03-05 11:00 EST",
+			},
+		},
+		"window": {
+			master: master(timespan, "RRULE:FREQ=DAILY"),
+			start:  time.Date(2020, 3, 10, 13, 15, 0, 0, time.UTC),
+			end:    time.Date(2020, 3, 12, 13, 0, 0, 0, time.UTC),
+			want: []string{
+				"2020-03-10 09:00 EDT",
+				"2020-03-11 09:00 EDT",
+			},
+		},
+		"all day": {
+			master: master(&EventDate{Date: civilDate(2020, 3, 6)},
+				"RRULE:FREQ=WEEKLY;COUNT=3", "EXDATE;VALUE=DATE:20200313"),
+			want: []string{
+				"2020-03-06 00:00 EST",
+				"2020-03-20 00:00 EDT",
+			},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			start, end := tt.start, tt.end
+			if start.IsZero() {
+				start, end = windowStart, windowEnd
+			}
+			got, err := ExpandEvent(tt.master, tt.overrides, start, end)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(eventStarts(t, got, ny), tt.want); diff != "" {
+				t.Errorf("ExpandEvent: (-got +want):\n%s", diff)
+			}
+			for _, e := range got {
+				if e.ID == "" && (e.MasterEventID != "master" || e.Title != "Standup" ||
+					len(e.Recurrence.RRule) != 0) {
+					t.Errorf("unexpected occurrence: %+v", e)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandEventDuration(t *testing.T) {
+	master := Event{
+		ID: "master",
+		When: &EventTimespan{
+			StartTime: time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 3, 2, 10, 30, 0, 0, time.UTC),
+		},
+		Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=DAILY;COUNT=2"}},
+	}
+	got, err := ExpandEvent(master, nil,
+		time.Date(2020, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Event{{
+		MasterEventID:     "master",
+		OriginalStartTime: time.Date(2020, 3, 3, 9, 0, 0, 0, time.UTC),
+		When: &EventTimespan{
+			StartTime: time.Date(2020, 3, 3, 9, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 3, 3, 10, 30, 0, 0, time.UTC),
+		},
+	}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ExpandEvent: (-got +want):\n%s", diff)
+	}
+}
+
+func TestExpandEventInvalid(t *testing.T) {
+	when := &EventTime{Time: time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)}
+	tests := map[string]Event{
+		"no when":        {Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=DAILY"}}},
+		"no freq":        {When: when, Recurrence: EventRecurrence{RRule: []string{"RRULE:COUNT=2"}}},
+		"hourly":         {When: when, Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=HOURLY"}}},
+		"bad byday":      {When: when, Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=WEEKLY;BYDAY=XX"}}},
+		"unknown part":   {When: when, Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=DAILY;BYHOUR=9"}}},
+		"bad exdate":     {When: when, Recurrence: EventRecurrence{RRule: []string{"EXDATE:yesterday"}}},
+		"unknown tzid":   {When: when, Recurrence: EventRecurrence{RRule: []string{"EXDATE;TZID=Nowhere:20200302"}}},
+		"unknown prefix": {When: when, Recurrence: EventRecurrence{RRule: []string{"EXRULE:FREQ=DAILY"}}},
+	}
+
+	for desc, e := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := ExpandEvent(e, nil, time.Time{}, time.Now()); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestExpandRecurringEvents(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2020, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	events := []Event{
+		{
+			ID:         "master",
+			When:       &EventTimespan{StartTime: at(2, 9), EndTime: at(2, 10)},
+			Recurrence: EventRecurrence{RRule: []string{"RRULE:FREQ=DAILY;COUNT=3"}},
+		},
+		{
+			ID:                "override",
+			MasterEventID:     "master",
+			OriginalStartTime: at(3, 9),
+			When:              &EventTimespan{StartTime: at(3, 15), EndTime: at(3, 16)},
+		},
+		{ID: "single", When: &EventTimespan{StartTime: at(3, 12), EndTime: at(3, 13)}},
+		{ID: "outside", When: &EventTimespan{StartTime: at(9, 12), EndTime: at(9, 13)}},
+		{
+			ID:                "orphan",
+			MasterEventID:     "elsewhere",
+			OriginalStartTime: at(1, 9),
+			When:              &EventTimespan{StartTime: at(2, 8), EndTime: at(2, 9)},
+		},
+	}
+
+	got, err := ExpandRecurringEvents(events, at(1, 0), at(5, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, e := range got {
+		ids = append(ids, e.ID)
+	}
+	want := []string{"orphan", "", "single", "override", ""}
+	if diff := cmp.Diff(ids, want); diff != "" {
+		t.Errorf("ExpandRecurringEvents: (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,131 @@
+package nylas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoomResource represents a room which can be booked by adding it as a
+// participant of an event.
+// See: https://docs.nylas.com/reference#room-resources
+type RoomResource struct {
+	Object string `json:"object"`
+	// Email address used to invite the room to events and query its free/busy.
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	// Number of people the room holds, zero when unknown.
+	Capacity int    `json:"capacity"`
+	Building string `json:"building"`
+	// FloorName as displayed, e.g. "7" or "Mezzanine".
+	FloorName string `json:"floor_name"`
+	// FloorNumber of the room, zero when unknown.
+	FloorNumber int `json:"floor_number"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting
+// capacity and floor number as either strings or numbers as providers
+// return both.
+func (r *RoomResource) UnmarshalJSON(data []byte) error {
+	type RoomResourceAlias RoomResource
+	ra := &struct {
+		*RoomResourceAlias
+		Capacity    json.RawMessage `json:"capacity"`
+		FloorNumber json.RawMessage `json:"floor_number"`
+	}{
+		RoomResourceAlias: (*RoomResourceAlias)(r),
+	}
+	if err := json.Unmarshal(data, ra); err != nil {
+		return err
+	}
+
+	var err error
+	if r.Capacity, err = looseInt(ra.Capacity); err != nil {
+		return fmt.Errorf("room resource capacity: %w", err)
+	}
+	if r.FloorNumber, err = looseInt(ra.FloorNumber); err != nil {
+		return fmt.Errorf("room resource floor number: %w", err)
+	}
+	return nil
+}
+
+// looseInt decodes an integer which may be encoded as a JSON string, missing,
+// null and empty values are zero.
+func looseInt(data json.RawMessage) (int, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return 0, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(s)
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return 0, fmt.Errorf("invalid integer %s", data)
+	}
+	return n, nil
+}
+
+// Participant returns the room as a participant to add to an event.
+func (r RoomResource) Participant() EventParticipant {
+	return EventParticipant{Name: r.Name, Email: r.Email}
+}
+
+// RoomResources returns the room resources of the account's organization.
+// See: https://docs.nylas.com/reference#get-resources
+func (c *Client) RoomResources(ctx context.Context) ([]RoomResource, error) {
+	req, err := c.newUserRequest(ctx, http.MethodGet, "/resources", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []RoomResource
+	return resp, c.do(req, &resp)
+}
+
+// AvailableRoomResources returns the rooms which have no busy time slots
+// overlapping start to end, using the FreeBusy method. Rooms missing from
+// the free/busy response are assumed to be unavailable.
+func (c *Client) AvailableRoomResources(
+	ctx context.Context, rooms []RoomResource, start, end time.Time,
+) ([]RoomResource, error) {
+	if len(rooms) == 0 {
+		return nil, nil
+	}
+	emails := make([]string, len(rooms))
+	for i, r := range rooms {
+		emails[i] = r.Email
+	}
+	freeBusy, err := c.FreeBusy(ctx, emails, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	free := make(map[string]bool, len(freeBusy))
+	for _, fb := range freeBusy {
+		email := strings.ToLower(fb.Email)
+		free[email] = true
+		for _, slot := range fb.TimeSlots {
+			if slot.Status != TimeSlotStatusFree &&
+				slot.StartTime.Before(end) && slot.EndTime.After(start) {
+				free[email] = false
+				break
+			}
+		}
+	}
+
+	var available []RoomResource
+	for _, r := range rooms {
+		if free[strings.ToLower(r.Email)] {
+			available = append(available, r)
+		}
+	}
+	return available, nil
+}
@@ -0,0 +1,122 @@
+package nylas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRoomResources(t *testing.T) {
+	accessToken := "accessToken"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodGet, "/resources")
+		_, _ = w.Write([]byte(`[{
+			"object": "room_resource",
+			"email": "training-room-1a@google.com",
+			"name": "Training Room 1A",
+			"capacity": "8",
+			"building": "West Building",
+			"floor_name": "7",
+			"floor_number": "7"
+		}, {
+			"object": "room_resource",
+			"email": "lobby@google.com",
+			"name": "Lobby",
+			"capacity": 20,
+			"floor_name": "Ground",
+			"floor_number": null
+		}]`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.RoomResources(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []RoomResource{
+		{
+			Object:      "room_resource",
+			Email:       "training-room-1a@google.com",
+			Name:        "Training Room 1A",
+			Capacity:    8,
+			Building:    "West Building",
+			FloorName:   "7",
+			FloorNumber: 7,
+		},
+		{
+			Object:    "room_resource",
+			Email:     "lobby@google.com",
+			Name:      "Lobby",
+			Capacity:  20,
+			FloorName: "Ground",
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("RoomResources: (-got +want):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(got[0].Participant(), EventParticipant{
+		Name:  "Training Room 1A",
+		Email: "training-room-1a@google.com",
+	}); diff != "" {
+		t.Errorf("Participant: (-got +want):\n%s", diff)
+	}
+}
+
+func TestRoomResourceInvalidCapacity(t *testing.T) {
+	var r RoomResource
+	if err := json.Unmarshal([]byte(`{"capacity": "eight"}`), &r); err == nil {
+		t.Error("expected error")
+	}
+	if err := json.Unmarshal([]byte(`{"floor_number": true}`), &r); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAvailableRoomResources(t *testing.T) {
+	accessToken := "accessToken"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertBasicAuth(t, r, accessToken, "")
+		assertMethodPath(t, r, http.MethodPost, "/calendars/free-busy")
+		_, _ = w.Write([]byte(`[{
+			"object": "free_busy",
+			"email": "a@example.com",
+			"time_slots": [{"object": "time_slot", "status": "busy", "start_time": 1409590800, "end_time": 1409594400}]
+		}, {
+			"object": "free_busy",
+			"email": "b@example.com",
+			"time_slots": [{"object": "time_slot", "status": "busy", "start_time": 1409594400, "end_time": 1409596200}]
+		}, {
+			"object": "free_busy",
+			"email": "C@example.com",
+			"time_slots": []
+		}]`))
+	}))
+	defer ts.Close()
+
+	rooms := []RoomResource{
+		{Email: "a@example.com"},
+		{Email: "b@example.com"},
+		{Email: "c@example.com"},
+		{Email: "missing@example.com"},
+	}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken(accessToken))
+	got, err := client.AvailableRoomResources(context.Background(), rooms,
+		time.Unix(1409594400, 0), time.Unix(1409598000, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []RoomResource{{Email: "a@example.com"}, {Email: "c@example.com"}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("AvailableRoomResources: (-got +want):\n%s", diff)
+	}
+}
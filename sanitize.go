@@ -0,0 +1,413 @@
+package nylas
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RemoteContentPolicy determines how remote images are handled when
+// sanitizing HTML.
+type RemoteContentPolicy int
+
+// Remote content policies.
+const (
+	// RemoteContentBlock removes remote images.
+	RemoteContentBlock RemoteContentPolicy = iota
+	// RemoteContentAllow keeps remote images as they are.
+	RemoteContentAllow
+	// RemoteContentProxy rewrites remote image URLs with
+	// SanitizeOptions.ProxyURL, removing them if it returns "".
+	RemoteContentProxy
+)
+
+// FileURLFunc returns the URL an inline file should be served from, or "" if
+// it should not be displayed.
+type FileURLFunc func(File) string
+
+// SanitizeOptions provides optional parameters to SanitizeHTML.
+type SanitizeOptions struct {
+	// RemoteContent determines how remote images are handled, by default
+	// they are removed.
+	RemoteContent RemoteContentPolicy
+	// ProxyURL rewrites remote image URLs when using RemoteContentProxy.
+	ProxyURL func(string) string
+	// KeepTrackingPixels keeps tiny or hidden images which are typically used
+	// to track when a message is opened.
+	KeepTrackingPixels bool
+
+	// Files and FileURL rewrite inline images referencing a file by its
+	// content ID with a cid: URL, see RewriteInlineImages. Inline images
+	// without a URL are removed.
+	Files   []File
+	FileURL FileURLFunc
+}
+
+var (
+	// sanitizeAllowedTags are the elements kept when sanitizing, others are
+	// either dropped along with their content or replaced by their content.
+	sanitizeAllowedTags = map[atom.Atom]bool{
+		atom.A: true, atom.Abbr: true, atom.Address: true, atom.B: true, atom.Big: true,
+		atom.Blockquote: true, atom.Br: true, atom.Caption: true, atom.Center: true,
+		atom.Cite: true, atom.Code: true, atom.Col: true, atom.Colgroup: true, atom.Dd: true,
+		atom.Del: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Em: true,
+		atom.Font: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+		atom.H5: true, atom.H6: true, atom.Hr: true, atom.I: true, atom.Img: true,
+		atom.Ins: true, atom.Kbd: true, atom.Li: true, atom.Ol: true, atom.P: true,
+		atom.Pre: true, atom.Q: true, atom.S: true, atom.Small: true, atom.Span: true,
+		atom.Strike: true, atom.Strong: true, atom.Sub: true, atom.Sup: true,
+		atom.Table: true, atom.Tbody: true, atom.Td: true, atom.Tfoot: true, atom.Th: true,
+		atom.Thead: true, atom.Tr: true, atom.Tt: true, atom.U: true, atom.Ul: true,
+	}
+	// sanitizeDroppedTags are the elements removed along with their content.
+	sanitizeDroppedTags = map[atom.Atom]bool{
+		atom.Script: true, atom.Style: true, atom.Head: true, atom.Title: true,
+		atom.Iframe: true, atom.Frame: true, atom.Frameset: true, atom.Object: true,
+		atom.Embed: true, atom.Applet: true, atom.Noscript: true, atom.Template: true,
+		atom.Svg: true, atom.Math: true, atom.Input: true, atom.Button: true,
+		atom.Select: true, atom.Textarea: true, atom.Audio: true, atom.Video: true,
+		atom.Meta: true, atom.Link: true, atom.Base: true,
+	}
+	sanitizeAllowedAttrs = map[string]bool{
+		"align": true, "alt": true, "bgcolor": true, "border": true, "cellpadding": true,
+		"cellspacing": true, "color": true, "colspan": true, "dir": true, "face": true,
+		"height": true, "href": true, "lang": true, "rowspan": true, "size": true,
+		"src": true, "style": true, "title": true, "valign": true, "width": true,
+	}
+	sanitizeAllowedStyles = map[string]bool{
+		"background-color": true, "border": true, "border-bottom": true,
+		"border-collapse": true, "border-color": true, "border-left": true,
+		"border-radius": true, "border-right": true, "border-spacing": true,
+		"border-style": true, "border-top": true, "border-width": true, "color": true,
+		"direction": true, "display": true, "font": true, "font-family": true,
+		"font-size": true, "font-style": true, "font-variant": true, "font-weight": true,
+		"height": true, "letter-spacing": true, "line-height": true, "list-style-type": true,
+		"margin": true, "margin-bottom": true, "margin-left": true, "margin-right": true,
+		"margin-top": true, "max-width": true, "min-width": true, "padding": true,
+		"padding-bottom": true, "padding-left": true, "padding-right": true,
+		"padding-top": true, "table-layout": true, "text-align": true,
+		"text-decoration": true, "text-indent": true, "text-transform": true,
+		"vertical-align": true, "white-space": true, "width": true, "word-break": true,
+		"word-wrap": true,
+	}
+	unsafeStyleValue = regexp.MustCompile(`(?i)url\s*\(|expression\s*\(|javascript:|@import|\\|<`)
+	safeLinkSchemes  = map[string]bool{"http": true, "https": true, "mailto": true, "tel": true}
+	safeDataImage    = regexp.MustCompile(`(?i)^data:image/(png|gif|jpeg|webp);base64,[a-z0-9+/=\s]*$`)
+)
+
+// SanitizeHTML makes an email HTML body safe to display in a web page.
+//
+// Only an allowlist of formatting elements, attributes and inline styles is
+// kept. Scripts, styles, forms, frames and embedded objects are removed along
+// with their content, links are made to open in a new window, and remote
+// images and tracking pixels are handled according to opts.
+func SanitizeHTML(body string, opts *SanitizeOptions) string {
+	if opts == nil {
+		opts = &SanitizeOptions{}
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(body), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		// The parser only fails on read errors, which strings.Reader
+		// never returns.
+		return ""
+	}
+
+	s := sanitizer{opts: opts, files: inlineFiles(opts.Files)}
+	var b bytes.Buffer
+	for _, n := range nodes {
+		for _, n := range s.sanitize(n) {
+			html.Render(&b, n) // nolint: errcheck
+		}
+	}
+	return b.String()
+}
+
+// SanitizedBody returns the message body sanitized with SanitizeHTML, using
+// the message files for inline images unless opts specifies them.
+func (m Message) SanitizedBody(opts *SanitizeOptions) string {
+	var o SanitizeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Files == nil {
+		o.Files = m.Files
+	}
+	return SanitizeHTML(m.Body, &o)
+}
+
+type sanitizer struct {
+	opts  *SanitizeOptions
+	files map[string]File
+}
+
+// sanitize returns the sanitized replacement for n, which is detached from
+// its parent.
+func (s sanitizer) sanitize(n *html.Node) []*html.Node {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{n}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	if sanitizeDroppedTags[n.DataAtom] || n.Namespace != "" {
+		return nil
+	}
+
+	var children []*html.Node
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		children = append(children, s.sanitize(c)...)
+	}
+	if !sanitizeAllowedTags[n.DataAtom] {
+		return children
+	}
+	for _, c := range children {
+		n.AppendChild(c)
+	}
+
+	// Tracking pixels are detected before attrs removes the styles which
+	// hide them.
+	if n.DataAtom == atom.Img && !s.opts.KeepTrackingPixels && isTrackingPixel(n) {
+		return nil
+	}
+	s.attrs(n)
+	if n.DataAtom == atom.Img && !s.image(n) {
+		return nil
+	}
+	if n.DataAtom == atom.A && htmlAttr(n, "href") != "" {
+		n.Attr = append(n.Attr,
+			html.Attribute{Key: "target", Val: "_blank"},
+			html.Attribute{Key: "rel", Val: "noopener noreferrer nofollow"},
+		)
+	}
+	return []*html.Node{n}
+}
+
+// attrs removes attributes and styles not in the allowlist and unsafe links.
+func (s sanitizer) attrs(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || !sanitizeAllowedAttrs[key] {
+			continue
+		}
+		switch key {
+		case "href":
+			if n.DataAtom != atom.A || !safeLink(a.Val) {
+				continue
+			}
+		case "src":
+			if n.DataAtom != atom.Img {
+				continue
+			}
+		case "style":
+			a.Val = sanitizeStyle(a.Val)
+			if a.Val == "" {
+				continue
+			}
+		}
+		a.Key = key
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+}
+
+// image rewrites the src of an img element according to the options,
+// reporting whether it should be kept.
+func (s sanitizer) image(n *html.Node) bool {
+	for i, a := range n.Attr {
+		if a.Key != "src" {
+			continue
+		}
+		src := strings.TrimSpace(a.Val)
+		lower := strings.ToLower(src)
+		switch {
+		case strings.HasPrefix(lower, "cid:"):
+			src = s.fileURL(src)
+		case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"),
+			strings.HasPrefix(lower, "//"):
+			switch s.opts.RemoteContent {
+			case RemoteContentAllow:
+			case RemoteContentProxy:
+				src = ""
+				if s.opts.ProxyURL != nil {
+					src = s.opts.ProxyURL(a.Val)
+				}
+			default:
+				src = ""
+			}
+		case safeDataImage.MatchString(src):
+		default:
+			src = ""
+		}
+		if src == "" {
+			return false
+		}
+		n.Attr[i].Val = src
+		return true
+	}
+	return false
+}
+
+func (s sanitizer) fileURL(cid string) string {
+	f, ok := s.files[contentID(cid)]
+	if !ok || s.opts.FileURL == nil {
+		return ""
+	}
+	return s.opts.FileURL(f)
+}
+
+// isTrackingPixel reports whether img is hidden, transparent or at most 1x1
+// pixels.
+func isTrackingPixel(img *html.Node) bool {
+	width, height := htmlAttr(img, "width"), htmlAttr(img, "height")
+	for _, decl := range strings.Split(htmlAttr(img, "style"), ";") {
+		name, value := splitStyle(decl)
+		// Ignore the priority, e.g. "1px !important".
+		if i := strings.IndexByte(value, '!'); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		value = strings.ToLower(value)
+		switch name {
+		case "width":
+			width = value
+		case "height":
+			height = value
+		case "display":
+			if value == "none" {
+				return true
+			}
+		case "visibility":
+			if value == "hidden" || value == "collapse" {
+				return true
+			}
+		case "opacity":
+			if n, err := strconv.ParseFloat(value, 64); err == nil && n <= 0 {
+				return true
+			}
+		}
+	}
+	return tinyDimension(width) && tinyDimension(height)
+}
+
+func tinyDimension(v string) bool {
+	v = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(v)), "px")
+	n, err := strconv.ParseFloat(v, 64)
+	return err == nil && n <= 1
+}
+
+// sanitizeStyle removes declarations not in the allowlist from an inline
+// style.
+func sanitizeStyle(style string) string {
+	var decls []string
+	for _, decl := range strings.Split(style, ";") {
+		name, value := splitStyle(decl)
+		if !sanitizeAllowedStyles[name] || value == "" || unsafeStyleValue.MatchString(value) {
+			continue
+		}
+		decls = append(decls, name+": "+value)
+	}
+	return strings.Join(decls, "; ")
+}
+
+func splitStyle(decl string) (name, value string) {
+	i := strings.IndexByte(decl, ':')
+	if i < 0 {
+		return "", ""
+	}
+	return strings.ToLower(strings.TrimSpace(decl[:i])), strings.TrimSpace(decl[i+1:])
+}
+
+// safeLink reports whether href is a link with an allowed scheme or a
+// fragment.
+func safeLink(href string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		return u.Host == "" && u.Path == "" && u.Fragment != ""
+	}
+	return safeLinkSchemes[strings.ToLower(u.Scheme)]
+}
+
+// inlineFiles maps files by their content ID.
+func inlineFiles(files []File) map[string]File {
+	m := make(map[string]File, len(files))
+	for _, f := range files {
+		if id := trimAngles(f.ContentID); id != "" {
+			m[id] = f
+		}
+	}
+	return m
+}
+
+// contentID returns the content ID referenced by a cid: URL.
+func contentID(cid string) string {
+	id := strings.TrimSpace(cid)[len("cid:"):]
+	if unescaped, err := url.PathUnescape(id); err == nil {
+		id = unescaped
+	}
+	return trimAngles(id)
+}
+
+// RewriteInlineImages replaces cid: references in the src attributes of an
+// HTML body with the URLs returned by fileURL for the file with the matching
+// File.ContentID. References to unknown files, or for which fileURL returns
+// "", are left unchanged.
+//
+// The rest of the body is left exactly as it is, use SanitizeHTML with
+// SanitizeOptions.FileURL to rewrite and sanitize together.
+func RewriteInlineImages(body string, files []File, fileURL FileURLFunc) string {
+	byID := inlineFiles(files)
+	z := html.NewTokenizer(strings.NewReader(body))
+	var b strings.Builder
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// The tokenizer only fails on EOF and read errors, which
+			// strings.Reader never returns.
+			return b.String()
+		}
+		// Token lower-cases names in place, so copy the raw token first.
+		raw := append([]byte(nil), z.Raw()...)
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			b.Write(raw)
+			continue
+		}
+
+		tok := z.Token()
+		rewritten := false
+		for i, a := range tok.Attr {
+			if a.Key != "src" || !strings.HasPrefix(strings.ToLower(strings.TrimSpace(a.Val)), "cid:") {
+				continue
+			}
+			f, ok := byID[contentID(a.Val)]
+			if !ok {
+				continue
+			}
+			if u := fileURL(f); u != "" {
+				tok.Attr[i].Val = u
+				rewritten = true
+			}
+		}
+		if rewritten {
+			b.WriteString(tok.String())
+		} else {
+			b.Write(raw)
+		}
+	}
+}
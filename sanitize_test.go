@@ -0,0 +1,122 @@
+package nylas
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	files := []File{
+		{ID: "file1", ContentID: "<logo@example.com>"},
+		{ID: "file2", ContentID: "chart@example.com"},
+	}
+	fileURL := func(f File) string { return "/files/" + f.ID }
+
+	tests := map[string]struct {
+		body string
+		opts *SanitizeOptions
+		want string
+	}{
+		"scripts and styles": {
+			body: `<html><head><style>body{color:red}</style><title>x</title></head>` +
+				`<body onload="evil()"><script>alert(1)</script><p class="x" onclick="evil()">Hi</p>` +
+				`<iframe src="https://example.com"></iframe><form><input name="q">Find</form></body></html>`,
+			want: `<p>Hi</p>Find`,
+		},
+		"links": {
+			body: `<a href="https://example.com">ok</a><a href="javascript:alert(1)">bad</a>` +
+				`<a href="mailto:a@example.com">mail</a>`,
+			want: `<a href="https://example.com" target="_blank" rel="noopener noreferrer nofollow">ok</a>` +
+				`<a>bad</a><a href="mailto:a@example.com" target="_blank" rel="noopener noreferrer nofollow">mail</a>`,
+		},
+		"styles": {
+			body: `<div style="color: red; position: fixed; background-image: url(https://t.example.com); ` +
+				`width: expression(alert(1)); FONT-WEIGHT:bold">x</div><span style="position:absolute">y</span>`,
+			want: `<div style="color: red; font-weight: bold">x</div><span>y</span>`,
+		},
+		"remote images blocked": {
+			body: `<img src="https://example.com/a.png" alt="a"><img src="data:image/png;base64,iVBORw0KGgo=">` +
+				`<img src="data:text/html;base64,PHNjcmlwdD4=">`,
+			want: `<img src="data:image/png;base64,iVBORw0KGgo="/>`,
+		},
+		"remote images allowed": {
+			body: `<img src="https://example.com/a.png" alt="a"><img src="https://t.example.com/o.gif" width="1" height="1">` +
+				`<img src="https://t.example.com/p.gif" style="display: none">`,
+			opts: &SanitizeOptions{RemoteContent: RemoteContentAllow},
+			want: `<img src="https://example.com/a.png" alt="a"/>`,
+		},
+		"hidden tracking pixels": {
+			body: `<img src="https://t.example.com/v.gif" style="visibility:hidden">` +
+				`<img src="https://t.example.com/o.gif" style="opacity: 0">` +
+				`<img src="https://t.example.com/i.gif" style="width: 1px !important; height: 1PX !important">` +
+				`<img src="https://t.example.com/d.gif" style="DISPLAY: None !important">` +
+				`<img src="https://example.com/a.png" style="opacity: 0.5; width: 10px">`,
+			opts: &SanitizeOptions{
+				RemoteContent: RemoteContentProxy,
+				ProxyURL:      func(u string) string { return "/proxy?url=" + u },
+			},
+			want: `<img src="/proxy?url=https://example.com/a.png" style="width: 10px"/>`,
+		},
+		"tracking pixels kept": {
+			body: `<img src="https://t.example.com/o.gif" width="1" height="1">`,
+			opts: &SanitizeOptions{RemoteContent: RemoteContentAllow, KeepTrackingPixels: true},
+			want: `<img src="https://t.example.com/o.gif" width="1" height="1"/>`,
+		},
+		"remote images proxied": {
+			body: `<img src="https://example.com/a.png">`,
+			opts: &SanitizeOptions{
+				RemoteContent: RemoteContentProxy,
+				ProxyURL:      func(u string) string { return "/proxy?url=" + u },
+			},
+			want: `<img src="/proxy?url=https://example.com/a.png"/>`,
+		},
+		"inline images": {
+			body: `<img src="cid:logo@example.com"><img src="CID:chart%40example.com"><img src="cid:unknown">`,
+			opts: &SanitizeOptions{Files: files, FileURL: fileURL},
+			want: `<img src="/files/file1"/><img src="/files/file2"/>`,
+		},
+		"unknown elements": {
+			body: `<custom><b>bold</b></custom><svg><a href="https://example.com">x</a></svg>text`,
+			want: `<b>bold</b>text`,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got := SanitizeHTML(tt.body, tt.opts)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("SanitizeHTML: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMessageSanitizedBody(t *testing.T) {
+	msg := Message{
+		Body:  `<p>Logo: <img src="cid:logo"></p>`,
+		Files: []File{{ID: "file1", ContentID: "<logo>"}},
+	}
+	got := msg.SanitizedBody(&SanitizeOptions{
+		FileURL: func(f File) string { return "/files/" + f.ID },
+	})
+	want := `<p>Logo: <img src="/files/file1"/></p>`
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("SanitizedBody: (-got +want):\n%s", diff)
+	}
+}
+
+func TestRewriteInlineImages(t *testing.T) {
+	files := []File{{ID: "file1", ContentID: "<logo@example.com>"}, {ID: "file2"}}
+	body := `<DIV Class=x><IMG SRC="cid:logo@example.com" alt="Logo"><img src='cid:other'>` +
+		`<script>var s = "<img src=cid:logo@example.com>";</script></DIV>`
+
+	got := RewriteInlineImages(body, files, func(f File) string {
+		return "https://files.example.com/" + f.ID + "?a=1&b=2"
+	})
+	want := `<DIV Class=x><img src="https://files.example.com/file1?a=1&amp;b=2" alt="Logo"><img src='cid:other'>` +
+		`<script>var s = "<img src=cid:logo@example.com>";</script></DIV>`
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("RewriteInlineImages: (-got +want):\n%s", diff)
+	}
+}
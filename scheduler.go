@@ -0,0 +1,401 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrScheduledSendNotFound is returned by a ScheduledSendStore when there is
+// no scheduled send with the given ID.
+var ErrScheduledSendNotFound = errors.New("scheduled send not found")
+
+// ScheduledSend is a draft waiting to be sent by a SendScheduler.
+type ScheduledSend struct {
+	// ID of the scheduled send, the ID of the draft when it was created.
+	ID      string       `json:"id"`
+	DraftID string       `json:"draft_id"`
+	Request DraftRequest `json:"request"`
+
+	// SendAt is when the draft should be sent.
+	SendAt time.Time `json:"send_at"`
+	// RetryLimit is when to stop retrying if sending fails, zero retries
+	// until the send is cancelled.
+	RetryLimit time.Time `json:"retry_limit"`
+
+	// Status of the send, one of the OutboxStatus constants. Sent sends are
+	// removed from the store, so are only seen if removing them failed, in
+	// which case SendDue removes them again.
+	Status string `json:"status"`
+	// NextAttemptAt is when the draft will next be sent.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	// MessageID of the sent message, only set once the draft has been sent.
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// ScheduledSendStore persists the drafts scheduled with a SendScheduler.
+type ScheduledSendStore interface {
+	// ScheduledSend returns the scheduled send with the given ID, or
+	// ErrScheduledSendNotFound if there is none.
+	ScheduledSend(ctx context.Context, id string) (ScheduledSend, error)
+	// ScheduledSends returns all the scheduled sends ordered by SendAt.
+	ScheduledSends(ctx context.Context) ([]ScheduledSend, error)
+	// DueScheduledSends returns the pending scheduled sends whose next
+	// attempt is at or before now, along with the sent scheduled sends
+	// which have not been removed.
+	DueScheduledSends(ctx context.Context, now time.Time) ([]ScheduledSend, error)
+	// SetScheduledSend stores a scheduled send, replacing any previous one
+	// with the same ID.
+	SetScheduledSend(ctx context.Context, send ScheduledSend) error
+	// DeleteScheduledSend removes the scheduled send with the given ID, it
+	// is not an error if there is none.
+	DeleteScheduledSend(ctx context.Context, id string) error
+}
+
+// MemoryScheduledSendStore is a ScheduledSendStore which keeps scheduled
+// sends in memory.
+type MemoryScheduledSendStore struct {
+	mu    sync.RWMutex
+	sends map[string]ScheduledSend
+}
+
+// NewMemoryScheduledSendStore returns a new empty MemoryScheduledSendStore.
+func NewMemoryScheduledSendStore() *MemoryScheduledSendStore {
+	return &MemoryScheduledSendStore{sends: make(map[string]ScheduledSend)}
+}
+
+// ScheduledSend implements the ScheduledSendStore interface.
+func (s *MemoryScheduledSendStore) ScheduledSend(_ context.Context, id string) (ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	send, ok := s.sends[id]
+	if !ok {
+		return ScheduledSend{}, ErrScheduledSendNotFound
+	}
+	return send, nil
+}
+
+// ScheduledSends implements the ScheduledSendStore interface.
+func (s *MemoryScheduledSendStore) ScheduledSends(_ context.Context) ([]ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedScheduledSends(s.sends, nil), nil
+}
+
+// DueScheduledSends implements the ScheduledSendStore interface.
+func (s *MemoryScheduledSendStore) DueScheduledSends(_ context.Context, now time.Time) ([]ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedScheduledSends(s.sends, dueAt(now)), nil
+}
+
+// SetScheduledSend implements the ScheduledSendStore interface.
+func (s *MemoryScheduledSendStore) SetScheduledSend(_ context.Context, send ScheduledSend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sends[send.ID] = send
+	return nil
+}
+
+// DeleteScheduledSend implements the ScheduledSendStore interface.
+func (s *MemoryScheduledSendStore) DeleteScheduledSend(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sends, id)
+	return nil
+}
+
+// FileScheduledSendStore is a ScheduledSendStore which keeps scheduled sends
+// in a JSON file, so they survive restarts. Sends are removed once sent, so
+// the file only holds the sends which are pending or failed.
+type FileScheduledSendStore struct {
+	mu    sync.RWMutex
+	sends *fileMap[ScheduledSend]
+}
+
+// NewFileScheduledSendStore returns a new FileScheduledSendStore using the
+// file at path, which is created on the first change if it does not exist.
+func NewFileScheduledSendStore(path string) (*FileScheduledSendStore, error) {
+	sends, err := loadFileMap[ScheduledSend](path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FileScheduledSendStore{sends: sends}, nil
+}
+
+// ScheduledSend implements the ScheduledSendStore interface.
+func (s *FileScheduledSendStore) ScheduledSend(_ context.Context, id string) (ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	send, ok := s.sends.m[id]
+	if !ok {
+		return ScheduledSend{}, ErrScheduledSendNotFound
+	}
+	return send, nil
+}
+
+// ScheduledSends implements the ScheduledSendStore interface.
+func (s *FileScheduledSendStore) ScheduledSends(_ context.Context) ([]ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedScheduledSends(s.sends.m, nil), nil
+}
+
+// DueScheduledSends implements the ScheduledSendStore interface.
+func (s *FileScheduledSendStore) DueScheduledSends(_ context.Context, now time.Time) ([]ScheduledSend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedScheduledSends(s.sends.m, dueAt(now)), nil
+}
+
+// SetScheduledSend implements the ScheduledSendStore interface.
+func (s *FileScheduledSendStore) SetScheduledSend(_ context.Context, send ScheduledSend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sends.set(send.ID, send)
+}
+
+// DeleteScheduledSend implements the ScheduledSendStore interface.
+func (s *FileScheduledSendStore) DeleteScheduledSend(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sends.delete(id)
+}
+
+func dueAt(now time.Time) func(ScheduledSend) bool {
+	return func(send ScheduledSend) bool {
+		return send.Status == OutboxStatusSent ||
+			send.Status == OutboxStatusPending && !send.NextAttemptAt.After(now)
+	}
+}
+
+// sortedScheduledSends returns the sends matching filter, or all if it is
+// nil, ordered by SendAt.
+func sortedScheduledSends(sends map[string]ScheduledSend, filter func(ScheduledSend) bool) []ScheduledSend {
+	var matched []ScheduledSend
+	for _, send := range sends {
+		if filter == nil || filter(send) {
+			matched = append(matched, send)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].SendAt.Equal(matched[j].SendAt) {
+			return matched[i].SendAt.Before(matched[j].SendAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched
+}
+
+// SendSchedulerOptions provides optional parameters to NewSendScheduler.
+type SendSchedulerOptions struct {
+	// PollInterval is how often Run checks for due drafts, defaults to one
+	// minute.
+	PollInterval time.Duration
+	// RetryDelay is how long to wait before retrying a send which failed with
+	// a server, rate limiting or network error, defaults to one minute. Sends
+	// failing with other errors are not retried.
+	RetryDelay time.Duration
+	// OnSent is called after a scheduled draft has been sent.
+	OnSent func(ScheduledSend, Message)
+	// OnFailed is called when a scheduled draft has failed to send and will
+	// not be retried.
+	OnFailed func(ScheduledSend)
+	// OnError is called by Run with store errors, which are retried at the
+	// next poll.
+	OnError func(error)
+}
+
+// SendScheduler sends drafts at a later time with SendDraft, for accounts
+// where the outbox endpoints are not available.
+//
+// Drafts are created when scheduled and kept in a ScheduledSendStore, so
+// pending sends survive restarts when using a persistent store.
+type SendScheduler struct {
+	client *Client
+	store  ScheduledSendStore
+	opts   SendSchedulerOptions
+	now    func() time.Time
+}
+
+// NewSendScheduler returns a new SendScheduler sending from the account the
+// client is authenticated as.
+func NewSendScheduler(client *Client, store ScheduledSendStore, opts *SendSchedulerOptions) *SendScheduler {
+	s := &SendScheduler{client: client, store: store, now: time.Now}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.PollInterval <= 0 {
+		s.opts.PollInterval = time.Minute
+	}
+	if s.opts.RetryDelay <= 0 {
+		s.opts.RetryDelay = time.Minute
+	}
+	return s
+}
+
+// Schedule creates a draft from draftReq and schedules it to be sent at
+// sendAt, retrying failed sends until retryLimit unless it is zero.
+func (s *SendScheduler) Schedule(
+	ctx context.Context, draftReq DraftRequest, sendAt, retryLimit time.Time,
+) (ScheduledSend, error) {
+	if !retryLimit.IsZero() && retryLimit.Before(sendAt) {
+		return ScheduledSend{}, errors.New("retry limit must not be before send at")
+	}
+
+	draft, err := s.client.CreateDraft(ctx, draftReq)
+	if err != nil {
+		return ScheduledSend{}, err
+	}
+	send := ScheduledSend{
+		ID:            draft.ID,
+		DraftID:       draft.ID,
+		Request:       draftReq,
+		SendAt:        sendAt,
+		RetryLimit:    retryLimit,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: sendAt,
+	}
+	if err := s.store.SetScheduledSend(ctx, send); err != nil {
+		// Don't leave a draft on the account which will never be sent.
+		_ = s.client.DeleteDraft(ctx, draft.ID, draft.Version)
+		return ScheduledSend{}, err
+	}
+	return send, nil
+}
+
+// Reschedule changes when a pending scheduled send is sent.
+func (s *SendScheduler) Reschedule(ctx context.Context, id string, sendAt time.Time) (ScheduledSend, error) {
+	send, err := s.store.ScheduledSend(ctx, id)
+	if err != nil {
+		return ScheduledSend{}, err
+	}
+	send.SendAt = sendAt
+	send.NextAttemptAt = sendAt
+	send.Status = OutboxStatusPending
+	send.Attempts = 0
+	send.LastError = ""
+	return send, s.store.SetScheduledSend(ctx, send)
+}
+
+// Cancel removes a scheduled send and deletes its draft.
+func (s *SendScheduler) Cancel(ctx context.Context, id string) error {
+	send, err := s.store.ScheduledSend(ctx, id)
+	if err != nil {
+		return err
+	}
+	if send.Status == OutboxStatusPending {
+		draft, err := s.client.Draft(ctx, send.DraftID)
+		if err == nil {
+			err = s.client.DeleteDraft(ctx, draft.ID, draft.Version)
+		}
+		if err != nil && !hasStatusCode(err, http.StatusNotFound) {
+			return err
+		}
+	}
+	return s.store.DeleteScheduledSend(ctx, id)
+}
+
+// Run sends due drafts every PollInterval until ctx is done. Store errors
+// are passed to OnError and the due drafts are retried at the next poll.
+func (s *SendScheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.SendDue(ctx); err != nil && ctx.Err() == nil && s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SendDue sends the drafts which are due, recording failures to be retried,
+// and removes sends which were sent but could not be removed before. Errors
+// are only returned for store failures.
+func (s *SendScheduler) SendDue(ctx context.Context) error {
+	due, err := s.store.DueScheduledSends(ctx, s.now())
+	if err != nil {
+		return err
+	}
+	for _, send := range due {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if send.Status == OutboxStatusSent {
+			err = s.store.DeleteScheduledSend(ctx, send.ID)
+		} else {
+			err = s.send(ctx, send)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SendScheduler) send(ctx context.Context, send ScheduledSend) error {
+	msg, err := s.sendDraft(ctx, send)
+	if err == nil {
+		// Record the send before removing it, so it isn't mistaken for a
+		// deleted draft and failed if removing it fails. The draft has been
+		// sent so this must happen even if ctx is done.
+		storeCtx := context.WithoutCancel(ctx)
+		send.Status = OutboxStatusSent
+		send.MessageID = msg.ID
+		if err := s.store.SetScheduledSend(storeCtx, send); err != nil {
+			return err
+		}
+		if s.opts.OnSent != nil {
+			s.opts.OnSent(send, msg)
+		}
+		return s.store.DeleteScheduledSend(storeCtx, send.ID)
+	}
+
+	send.Attempts++
+	send.LastError = err.Error()
+	send.NextAttemptAt = s.now().Add(s.opts.RetryDelay)
+	// Only errors which may go away are retried. This excludes a missing
+	// draft, which has been deleted or sent before the scheduled send could
+	// be removed, so retrying could send it twice.
+	if !isTransientError(err) ||
+		!send.RetryLimit.IsZero() && send.NextAttemptAt.After(send.RetryLimit) {
+		send.Status = OutboxStatusFailed
+	}
+	if err := s.store.SetScheduledSend(ctx, send); err != nil {
+		return err
+	}
+	if send.Status == OutboxStatusFailed && s.opts.OnFailed != nil {
+		s.opts.OnFailed(send)
+	}
+	return nil
+}
+
+// sendDraft sends the latest version of the draft, so edits made after it
+// was scheduled are included.
+func (s *SendScheduler) sendDraft(ctx context.Context, send ScheduledSend) (Message, error) {
+	draft, err := s.client.Draft(ctx, send.DraftID)
+	if err != nil {
+		return Message{}, err
+	}
+	return s.client.SendDraft(ctx, draft.ID, draft.Version)
+}
+
+// isTransientError reports whether a request may succeed if retried, which is
+// the case for server errors, rate limiting and network errors.
+func isTransientError(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError ||
+		apiErr.StatusCode == http.StatusTooManyRequests
+}
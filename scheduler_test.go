@@ -0,0 +1,423 @@
+package nylas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// draftServer is a fake API server storing drafts in memory.
+type draftServer struct {
+	t        *testing.T
+	mu       sync.Mutex
+	drafts   map[string]Draft
+	created  int
+	sent     []string
+	failSend int
+	// failStatus is the status code of failed sends, defaults to 503.
+	failStatus int
+}
+
+func (s *draftServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/drafts":
+		var req DraftRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("decoding request: %v", err)
+		}
+		d := Draft{Version: 0}
+		s.created++
+		d.ID = fmt.Sprintf("draft%d", s.created)
+		d.Subject = req.Subject
+		s.drafts[d.ID] = d
+		_ = json.NewEncoder(w).Encode(d)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/drafts/"):
+		d, ok := s.drafts[strings.TrimPrefix(r.URL.Path, "/drafts/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(d)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/drafts/"):
+		delete(s.drafts, strings.TrimPrefix(r.URL.Path, "/drafts/"))
+	case r.Method == http.MethodPost && r.URL.Path == "/send":
+		if s.failSend > 0 {
+			s.failSend--
+			if s.failStatus == 0 {
+				s.failStatus = http.StatusServiceUnavailable
+			}
+			w.WriteHeader(s.failStatus)
+			_, _ = w.Write([]byte(`{"message": "Provider unavailable"}`))
+			return
+		}
+		var req struct {
+			DraftID string `json:"draft_id"`
+			Version int    `json:"version"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.t.Errorf("decoding request: %v", err)
+		}
+		d, ok := s.drafts[req.DraftID]
+		if !ok || d.Version != req.Version {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delete(s.drafts, req.DraftID)
+		s.sent = append(s.sent, d.Subject)
+		_ = json.NewEncoder(w).Encode(Message{ID: "msg-" + d.ID})
+	default:
+		s.t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func TestSendScheduler(t *testing.T) {
+	srv := &draftServer{t: t, drafts: make(map[string]Draft)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	now := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+	var sent []string
+	var failed []string
+	store := NewMemoryScheduledSendStore()
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	scheduler := NewSendScheduler(client, store, &SendSchedulerOptions{
+		RetryDelay: time.Minute,
+		OnSent:     func(s ScheduledSend, m Message) { sent = append(sent, m.ID) },
+		OnFailed:   func(s ScheduledSend) { failed = append(failed, s.ID) },
+	})
+	scheduler.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	later, err := scheduler.Schedule(ctx, DraftRequest{Subject: "later"}, now.Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	soon, err := scheduler.Schedule(ctx, DraftRequest{Subject: "soon"}, now.Add(time.Minute), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancelled, err := scheduler.Schedule(ctx, DraftRequest{Subject: "cancelled"}, now, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := scheduler.Cancel(ctx, cancelled.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := scheduler.Schedule(ctx, DraftRequest{}, now, now.Add(-time.Second)); err == nil {
+		t.Error("expected error for retry limit before send at")
+	}
+
+	// Nothing is due yet.
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(srv.sent) != 0 {
+		t.Errorf("unexpected sends: %v", srv.sent)
+	}
+
+	// The first attempt fails and can't be retried within the retry limit.
+	now = now.Add(time.Minute)
+	srv.failSend = 1
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := store.ScheduledSend(ctx, soon.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != OutboxStatusFailed || got.Attempts != 1 || got.LastError == "" {
+		t.Errorf("unexpected failed send: %+v", got)
+	}
+	if diff := cmp.Diff(failed, []string{soon.ID}); diff != "" {
+		t.Errorf("failed: (-got +want):\n%s", diff)
+	}
+
+	// A failure without a retry limit is retried after the delay.
+	now = now.Add(time.Hour)
+	srv.failSend = 1
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(srv.sent, []string{"later"}); diff != "" {
+		t.Errorf("sent: (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(sent, []string{"msg-" + later.DraftID}); diff != "" {
+		t.Errorf("OnSent: (-got +want):\n%s", diff)
+	}
+
+	remaining, err := store.ScheduledSends(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != soon.ID {
+		t.Errorf("unexpected remaining sends: %+v", remaining)
+	}
+
+	// Errors which won't go away by retrying fail the send straight away.
+	rejected, err := scheduler.Schedule(ctx, DraftRequest{Subject: "rejected"}, now, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.failSend, srv.failStatus = 1, http.StatusPaymentRequired
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := store.ScheduledSend(ctx, rejected.ID); got.Status != OutboxStatusFailed || got.Attempts != 1 {
+		t.Errorf("unexpected rejected send: %+v", got)
+	}
+}
+
+// failingScheduledSendStore is a MemoryScheduledSendStore whose methods fail
+// while their fail count is above zero, and whose changes fail once the
+// context is done as a database backed store would.
+type failingScheduledSendStore struct {
+	*MemoryScheduledSendStore
+	mu                           sync.Mutex
+	failDue, failSet, failDelete int
+}
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+func (s *failingScheduledSendStore) fail(n *int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if *n > 0 {
+		*n--
+		return true
+	}
+	return false
+}
+
+func (s *failingScheduledSendStore) DueScheduledSends(ctx context.Context, now time.Time) ([]ScheduledSend, error) {
+	if s.fail(&s.failDue) {
+		return nil, errStoreUnavailable
+	}
+	return s.MemoryScheduledSendStore.DueScheduledSends(ctx, now)
+}
+
+func (s *failingScheduledSendStore) SetScheduledSend(ctx context.Context, send ScheduledSend) error {
+	if s.fail(&s.failSet) {
+		return errStoreUnavailable
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryScheduledSendStore.SetScheduledSend(ctx, send)
+}
+
+func (s *failingScheduledSendStore) DeleteScheduledSend(ctx context.Context, id string) error {
+	if s.fail(&s.failDelete) {
+		return errStoreUnavailable
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryScheduledSendStore.DeleteScheduledSend(ctx, id)
+}
+
+func TestSendSchedulerStoreErrors(t *testing.T) {
+	srv := &draftServer{t: t, drafts: make(map[string]Draft)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var sent, failed []string
+	var errs []error
+	store := &failingScheduledSendStore{MemoryScheduledSendStore: NewMemoryScheduledSendStore()}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"))
+	scheduler := NewSendScheduler(client, store, &SendSchedulerOptions{
+		PollInterval: time.Millisecond,
+		OnSent: func(s ScheduledSend, m Message) {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, m.ID)
+		},
+		OnFailed: func(s ScheduledSend) { failed = append(failed, s.ID) },
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+	ctx := context.Background()
+
+	// The draft is deleted if the scheduled send can't be stored.
+	store.failSet = 1
+	if _, err := scheduler.Schedule(ctx, DraftRequest{Subject: "unstored"}, time.Now(), time.Time{}); err == nil {
+		t.Error("expected store error")
+	}
+	if len(srv.drafts) != 0 {
+		t.Errorf("unexpected drafts: %+v", srv.drafts)
+	}
+
+	// A sent draft which couldn't be removed is removed on the next run
+	// rather than sent again or failed.
+	send, err := scheduler.Schedule(ctx, DraftRequest{Subject: "sent"}, time.Now(), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.failDelete = 1
+	if err := scheduler.SendDue(ctx); !errors.Is(err, errStoreUnavailable) {
+		t.Fatalf("expected store error, got: %v", err)
+	}
+	got, err := store.ScheduledSend(ctx, send.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Status != OutboxStatusSent || got.MessageID != "msg-"+send.DraftID {
+		t.Errorf("unexpected sent send: %+v", got)
+	}
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.ScheduledSend(ctx, send.ID); err != ErrScheduledSendNotFound {
+		t.Errorf("expected sent send to be removed, got: %v", err)
+	}
+	if len(srv.sent) != 1 || len(failed) != 0 {
+		t.Errorf("got sent %v and failed %v", srv.sent, failed)
+	}
+
+	// Run keeps polling after store errors.
+	if _, err := scheduler.Schedule(ctx, DraftRequest{Subject: "run"}, time.Now(), time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.failDue = 2
+	runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- scheduler.Run(runCtx) }()
+	for {
+		mu.Lock()
+		n := len(sent)
+		mu.Unlock()
+		if n == 2 || runCtx.Err() != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected Run error: %v", err)
+	}
+	if len(sent) != 2 || len(errs) != 2 {
+		t.Errorf("got %d sent and errors %v", len(sent), errs)
+	}
+}
+
+// cancelAfterSendTransport cancels a context once a draft has been sent.
+type cancelAfterSendTransport struct {
+	cancel func()
+}
+
+func (t cancelAfterSendTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || r.URL.Path != "/send" {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.cancel()
+	return resp, nil
+}
+
+func TestSendSchedulerStoppedAfterSend(t *testing.T) {
+	srv := &draftServer{t: t, drafts: make(map[string]Draft)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &failingScheduledSendStore{MemoryScheduledSendStore: NewMemoryScheduledSendStore()}
+	client := NewClient("", "", withTestServer(ts), WithAccessToken("accessToken"),
+		WithHTTPClient(&http.Client{Transport: cancelAfterSendTransport{cancel: cancel}}))
+	scheduler := NewSendScheduler(client, store, nil)
+
+	send, err := scheduler.Schedule(ctx, DraftRequest{Subject: "sent"}, time.Now(), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := scheduler.SendDue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.ScheduledSend(context.Background(), send.ID); err != ErrScheduledSendNotFound {
+		t.Errorf("expected sent send to be removed, got: %v", err)
+	}
+}
+
+func TestFileScheduledSendStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nylas")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "scheduled.json")
+
+	store, err := NewFileScheduledSendStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	at := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+	sends := []ScheduledSend{
+		{ID: "b", DraftID: "b", Request: DraftRequest{Subject: "b"}, SendAt: at.Add(time.Hour),
+			NextAttemptAt: at.Add(time.Hour), Status: OutboxStatusPending},
+		{ID: "a", DraftID: "a", SendAt: at, NextAttemptAt: at, Status: OutboxStatusPending},
+		{ID: "c", DraftID: "c", SendAt: at, NextAttemptAt: at, Status: OutboxStatusFailed},
+	}
+	for _, send := range sends {
+		if err := store.SetScheduledSend(ctx, send); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := store.DeleteScheduledSend(ctx, "c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reopening the store loads the saved sends.
+	store, err = NewFileScheduledSendStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := store.ScheduledSends(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, []ScheduledSend{sends[1], sends[0]}, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("ScheduledSends: (-got +want):\n%s", diff)
+	}
+
+	due, err := store.DueScheduledSends(ctx, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != "a" {
+		t.Errorf("unexpected due sends: %+v", due)
+	}
+	if _, err := store.ScheduledSend(ctx, "c"); err != ErrScheduledSendNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package nylas
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DraftTemplateConfig contains the templates and settings used to create a
+// DraftTemplate.
+type DraftTemplateConfig struct {
+	// Subject is a text/template for the draft subject.
+	Subject string
+	// HTMLBody is an html/template for the draft body.
+	HTMLBody string
+	// TextBody is a text/template for the plain text alternative of the body,
+	// generated from HTMLBody when empty. If only TextBody is given the draft
+	// body is generated from it. See RenderedDraft.Text for how it is used.
+	TextBody string
+	// Funcs are added to all the templates.
+	Funcs map[string]interface{}
+
+	// Required variables which must be set to a non-empty value in the data
+	// passed to Render. Variables used by the templates must always be set.
+	Required []string
+	// FileIDs of previously uploaded files to attach to each draft.
+	FileIDs []string
+	// Tracking options set on each draft.
+	Tracking *Tracking
+}
+
+// DraftTemplate renders drafts from Go templates with per-recipient data, for
+// use with CreateDraft or SendDirectly.
+type DraftTemplate struct {
+	subject  *texttemplate.Template
+	html     *htmltemplate.Template
+	text     *texttemplate.Template
+	required []string
+	fileIDs  []string
+	tracking *Tracking
+}
+
+// RenderedDraft is a draft rendered from a DraftTemplate.
+type RenderedDraft struct {
+	DraftRequest
+	// Text is the plain text alternative of the HTML body.
+	//
+	// It is not part of the DraftRequest and so is not sent by CreateDraft or
+	// SendDirectly, as the API generates the plain text part of a message
+	// from its HTML body. Callers must handle it themselves, for example to
+	// build a raw MIME message or to show a preview.
+	Text string
+}
+
+// MissingVariablesError is returned when rendering a DraftTemplate with data
+// which is missing required variables.
+type MissingVariablesError struct {
+	Names []string
+}
+
+// Error implements the error interface.
+func (e *MissingVariablesError) Error() string {
+	return "missing template variables: " + strings.Join(e.Names, ", ")
+}
+
+// NewDraftTemplate parses the templates in cfg.
+func NewDraftTemplate(cfg DraftTemplateConfig) (*DraftTemplate, error) {
+	t := &DraftTemplate{
+		required: cfg.Required,
+		fileIDs:  cfg.FileIDs,
+		tracking: cfg.Tracking,
+	}
+
+	var err error
+	if t.subject, err = parseTextTemplate("subject", cfg.Subject, cfg.Funcs); err != nil {
+		return nil, err
+	}
+	if cfg.TextBody != "" {
+		if t.text, err = parseTextTemplate("text", cfg.TextBody, cfg.Funcs); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HTMLBody != "" {
+		t.html, err = htmltemplate.New("html").
+			Option("missingkey=error").
+			Funcs(htmltemplate.FuncMap(cfg.Funcs)).
+			Parse(cfg.HTMLBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func parseTextTemplate(name, text string, funcs map[string]interface{}) (*texttemplate.Template, error) {
+	return texttemplate.New(name).
+		Option("missingkey=error").
+		Funcs(texttemplate.FuncMap(funcs)).
+		Parse(text)
+}
+
+// Validate returns a MissingVariablesError if data is missing any of the
+// required variables.
+func (t *DraftTemplate) Validate(data map[string]interface{}) error {
+	var missing []string
+	for _, name := range t.required {
+		if v, ok := data[name]; !ok || v == nil || v == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &MissingVariablesError{Names: missing}
+	}
+	return nil
+}
+
+// Render validates data and renders a draft addressed to the given
+// recipients.
+func (t *DraftTemplate) Render(to []Participant, data map[string]interface{}) (RenderedDraft, error) {
+	if err := t.Validate(data); err != nil {
+		return RenderedDraft{}, err
+	}
+
+	var b bytes.Buffer
+	if err := t.subject.Execute(&b, data); err != nil {
+		return RenderedDraft{}, err
+	}
+	// Subjects are a single line, so fold any newlines the template added.
+	subject := strings.Join(strings.Fields(b.String()), " ")
+
+	var body, text string
+	if t.html != nil {
+		b.Reset()
+		if err := t.html.Execute(&b, data); err != nil {
+			return RenderedDraft{}, err
+		}
+		body = b.String()
+	}
+	if t.text != nil {
+		b.Reset()
+		if err := t.text.Execute(&b, data); err != nil {
+			return RenderedDraft{}, err
+		}
+		text = b.String()
+	}
+	switch {
+	case t.html == nil && t.text != nil:
+		body = textToHTML(text)
+	case t.html != nil && t.text == nil:
+		text = HTMLToText(body)
+	}
+
+	draft := RenderedDraft{
+		DraftRequest: DraftRequest{
+			Subject: subject,
+			To:      to,
+			Body:    body,
+		},
+		Text: text,
+	}
+	if len(t.fileIDs) > 0 {
+		draft.FileIDs = append([]string(nil), t.fileIDs...)
+	}
+	if t.tracking != nil {
+		tracking := *t.tracking
+		draft.Tracking = &tracking
+	}
+	return draft, nil
+}
+
+// textToHTML converts a plain text body to HTML.
+func textToHTML(text string) string {
+	lines := splitLines(strings.TrimRight(text, "\r\n"))
+	for i, line := range lines {
+		lines[i] = htmltemplate.HTMLEscapeString(line)
+	}
+	return "<div>" + strings.Join(lines, "<br>") + "</div>"
+}
@@ -0,0 +1,116 @@
+package nylas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDraftTemplate(t *testing.T) {
+	to := []Participant{{Name: "Alice", Email: "alice@example.com"}}
+	data := map[string]interface{}{"Name": "Alice & Bob", "Count": 3}
+
+	tests := map[string]struct {
+		cfg  DraftTemplateConfig
+		want RenderedDraft
+	}{
+		"html": {
+			cfg: DraftTemplateConfig{
+				Subject:  "{{.Count}} new\nnotifications",
+				HTMLBody: "<p>Hi {{.Name}},</p><p>You have {{.Count | double}} points.</p>",
+				Funcs:    map[string]interface{}{"double": func(n int) int { return n * 2 }},
+				FileIDs:  []string{"file1"},
+				Tracking: &Tracking{Opens: true},
+			},
+			want: RenderedDraft{
+				DraftRequest: DraftRequest{
+					Subject:  "3 new notifications",
+					To:       to,
+					Body:     "<p>Hi Alice &amp; Bob,</p><p>You have 6 points.</p>",
+					FileIDs:  []string{"file1"},
+					Tracking: &Tracking{Opens: true},
+				},
+				Text: "Hi Alice & Bob,\n\nYou have 6 points.",
+			},
+		},
+		"text": {
+			cfg: DraftTemplateConfig{
+				Subject:  "Hello",
+				TextBody: "Hi {{.Name}},\n<{{.Count}}>\n",
+			},
+			want: RenderedDraft{
+				DraftRequest: DraftRequest{
+					Subject: "Hello",
+					To:      to,
+					Body:    "<div>Hi Alice &amp; Bob,<br>&lt;3&gt;</div>",
+				},
+				Text: "Hi Alice & Bob,\n<3>\n",
+			},
+		},
+		"html and text": {
+			cfg: DraftTemplateConfig{
+				Subject:  "Hello",
+				HTMLBody: "<b>{{.Name}}</b>",
+				TextBody: "*{{.Name}}*",
+			},
+			want: RenderedDraft{
+				DraftRequest: DraftRequest{
+					Subject: "Hello",
+					To:      to,
+					Body:    "<b>Alice &amp; Bob</b>",
+				},
+				Text: "*Alice & Bob*",
+			},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			tmpl, err := NewDraftTemplate(tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := tmpl.Render(to, data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Render: (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDraftTemplateErrors(t *testing.T) {
+	if _, err := NewDraftTemplate(DraftTemplateConfig{Subject: "{{.Name"}); err == nil {
+		t.Error("expected parse error")
+	}
+
+	tmpl, err := NewDraftTemplate(DraftTemplateConfig{
+		Subject:  "Hi {{.Name}}",
+		TextBody: "{{.Code}}",
+		Required: []string{"Name", "Code", "Team"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = tmpl.Render(nil, map[string]interface{}{"Name": "", "Team": "x"})
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected MissingVariablesError, got: %v", err)
+	}
+	if diff := cmp.Diff(missing.Names, []string{"Code", "Name"}); diff != "" {
+		t.Errorf("MissingVariablesError: (-got +want):\n%s", diff)
+	}
+
+	// Variables used by the templates are required even if not listed.
+	tmpl, err = NewDraftTemplate(DraftTemplateConfig{Subject: "Hi {{.Name}}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tmpl.Render(nil, map[string]interface{}{}); err == nil {
+		t.Error("expected error for missing variable")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
@@ -68,18 +69,18 @@ type ThreadsOptions struct {
 	// Return threads with one or more unread messages
 	Unread   *bool  `url:"unread,omitempty"`
 	Filename string `url:"filename,omitempty"`
-	// Return threads whose most recent message was received before this
-	// Unix-based timestamp.
-	LastMessageBefore int64 `url:"last_message_before,omitempty"`
-	// Return threads whose most recent message was received after this
-	// Unix-based timestamp.
-	LastMessageAfter int64 `url:"last_message_after,omitempty"`
-	// Return threads whose first message was received before this
-	// Unix-based timestamp.
-	StartedBefore int64 `url:"started_before,omitempty"`
-	// Return threads whose first message was received after this
-	// Unix-based timestamp.
-	StartedAfter int64 `url:"started_after,omitempty"`
+	// Return threads whose most recent message was received before this time,
+	// sent as a Unix timestamp.
+	LastMessageBefore time.Time `url:"last_message_before,unix,omitempty"`
+	// Return threads whose most recent message was received after this time,
+	// sent as a Unix timestamp.
+	LastMessageAfter time.Time `url:"last_message_after,unix,omitempty"`
+	// Return threads whose first message was received before this time,
+	// sent as a Unix timestamp.
+	StartedBefore time.Time `url:"started_before,unix,omitempty"`
+	// Return threads whose first message was received after this time,
+	// sent as a Unix timestamp.
+	StartedAfter time.Time `url:"started_after,unix,omitempty"`
 }
 
 // Threads returns threads which match the filter specified by parameters.
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -47,12 +48,12 @@ func TestThreads(t *testing.T) {
 		Filename:          "filename",
 		From:              "d@example.com",
 		In:                "in",
-		LastMessageAfter:  4,
-		LastMessageBefore: 3,
+		LastMessageAfter:  time.Unix(4, 0),
+		LastMessageBefore: time.Unix(3, 0),
 		Limit:             1,
 		Offset:            2,
-		StartedAfter:      6,
-		StartedBefore:     5,
+		StartedAfter:      time.Unix(6, 0),
+		StartedBefore:     time.Unix(5, 0),
 		Subject:           "subject",
 		To:                "c@example.com",
 		Unread:            Bool(true),
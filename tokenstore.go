@@ -0,0 +1,176 @@
+package nylas
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when no access token is known
+// for an account.
+var ErrTokenNotFound = errors.New("access token not found")
+
+// TokenStore maps Nylas account IDs to their access tokens.
+type TokenStore interface {
+	// AccessToken returns the access token for the account with the given
+	// ID, or ErrTokenNotFound if there is none.
+	AccessToken(ctx context.Context, accountID string) (string, error)
+}
+
+// WritableTokenStore is a TokenStore which can also add and remove access
+// tokens, as required by AccountPool.
+type WritableTokenStore interface {
+	TokenStore
+	// SetAccessToken stores the access token for the account with the given
+	// ID, replacing any previous token.
+	SetAccessToken(ctx context.Context, accountID, token string) error
+	// DeleteAccessToken removes the access token for the account with the
+	// given ID, it is not an error if there is none.
+	DeleteAccessToken(ctx context.Context, accountID string) error
+	// DeleteAccessTokenIf removes the access token for the account with the
+	// given ID only if it is still token, it is not an error if it is not.
+	DeleteAccessTokenIf(ctx context.Context, accountID, token string) error
+}
+
+// MemoryTokenStore is a WritableTokenStore which keeps access tokens in
+// memory.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+}
+
+// NewMemoryTokenStore returns a new empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]string)}
+}
+
+// AccessToken implements the TokenStore interface.
+func (s *MemoryTokenStore) AccessToken(_ context.Context, accountID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens[accountID]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// SetAccessToken implements the WritableTokenStore interface.
+func (s *MemoryTokenStore) SetAccessToken(_ context.Context, accountID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[accountID] = token
+	return nil
+}
+
+// DeleteAccessToken implements the WritableTokenStore interface.
+func (s *MemoryTokenStore) DeleteAccessToken(_ context.Context, accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, accountID)
+	return nil
+}
+
+// DeleteAccessTokenIf implements the WritableTokenStore interface.
+func (s *MemoryTokenStore) DeleteAccessTokenIf(_ context.Context, accountID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens[accountID] == token {
+		delete(s.tokens, accountID)
+	}
+	return nil
+}
+
+// FileTokenStore is a WritableTokenStore which keeps access tokens in a file
+// encrypted with AES-GCM, with a new nonce each time the file is written.
+type FileTokenStore struct {
+	aead cipher.AEAD
+
+	mu     sync.RWMutex
+	tokens *fileMap[string]
+}
+
+// NewFileTokenStore returns a new FileTokenStore using the file at path,
+// which is created on the first change if it does not exist.
+//
+// The key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+// AES-256 respectively.
+func NewFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileTokenStore{aead: aead}
+	s.tokens, err = loadFileMap[string](path, s.seal, s.open)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AccessToken implements the TokenStore interface.
+func (s *FileTokenStore) AccessToken(_ context.Context, accountID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens.m[accountID]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return token, nil
+}
+
+// SetAccessToken implements the WritableTokenStore interface.
+func (s *FileTokenStore) SetAccessToken(_ context.Context, accountID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens.set(accountID, token)
+}
+
+// DeleteAccessToken implements the WritableTokenStore interface.
+func (s *FileTokenStore) DeleteAccessToken(_ context.Context, accountID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens.delete(accountID)
+}
+
+// DeleteAccessTokenIf implements the WritableTokenStore interface.
+func (s *FileTokenStore) DeleteAccessTokenIf(_ context.Context, accountID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.tokens.m[accountID]; !ok || prev != token {
+		return nil
+	}
+	return s.tokens.delete(accountID)
+}
+
+// seal encrypts the token file, prefixing it with the nonce.
+func (s *FileTokenStore) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// open decrypts the token file.
+func (s *FileTokenStore) open(data []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("token file too short")
+	}
+	plain, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt token file: %w", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,100 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testTokenStore(t *testing.T, tokens map[string]string) WritableTokenStore {
+	t.Helper()
+	s := NewMemoryTokenStore()
+	for accountID, token := range tokens {
+		if err := s.SetAccessToken(context.Background(), accountID, token); err != nil {
+			t.Fatalf("set access token: %v", err)
+		}
+	}
+	return s
+}
+
+func testTokenStoreRoundTrip(t *testing.T, s WritableTokenStore) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.AccessToken(ctx, "a"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("missing token: got error %v; want %v", err, ErrTokenNotFound)
+	}
+
+	if err := s.SetAccessToken(ctx, "a", "tokenA"); err != nil {
+		t.Fatalf("set access token: %v", err)
+	}
+	if got, err := s.AccessToken(ctx, "a"); err != nil || got != "tokenA" {
+		t.Errorf("access token: got %q, %v; want %q", got, err, "tokenA")
+	}
+
+	if err := s.DeleteAccessToken(ctx, "a"); err != nil {
+		t.Fatalf("delete access token: %v", err)
+	}
+	if err := s.DeleteAccessToken(ctx, "a"); err != nil {
+		t.Fatalf("delete missing access token: %v", err)
+	}
+	if _, err := s.AccessToken(ctx, "a"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("deleted token: got error %v; want %v", err, ErrTokenNotFound)
+	}
+
+	// Only the given token is deleted, not one which replaced it.
+	if err := s.SetAccessToken(ctx, "a", "tokenB"); err != nil {
+		t.Fatalf("set access token: %v", err)
+	}
+	if err := s.DeleteAccessTokenIf(ctx, "a", "tokenA"); err != nil {
+		t.Fatalf("delete replaced access token: %v", err)
+	}
+	if got, err := s.AccessToken(ctx, "a"); err != nil || got != "tokenB" {
+		t.Errorf("replaced token: got %q, %v; want %q", got, err, "tokenB")
+	}
+	if err := s.DeleteAccessTokenIf(ctx, "a", "tokenB"); err != nil {
+		t.Fatalf("delete access token if: %v", err)
+	}
+	if _, err := s.AccessToken(ctx, "a"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("deleted token: got error %v; want %v", err, ErrTokenNotFound)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStoreRoundTrip(t, NewMemoryTokenStore())
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nylas")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "tokens")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	s, err := NewFileTokenStore(path, key)
+	if err != nil {
+		t.Fatalf("new file token store: %v", err)
+	}
+	testTokenStoreRoundTrip(t, s)
+
+	ctx := context.Background()
+	if err := s.SetAccessToken(ctx, "b", "tokenB"); err != nil {
+		t.Fatalf("set access token: %v", err)
+	}
+
+	reopened, err := NewFileTokenStore(path, key)
+	if err != nil {
+		t.Fatalf("reopen file token store: %v", err)
+	}
+	if got, err := reopened.AccessToken(ctx, "b"); err != nil || got != "tokenB" {
+		t.Errorf("reopened access token: got %q, %v; want %q", got, err, "tokenB")
+	}
+
+	if _, err := NewFileTokenStore(path, []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Error("expected error opening with the wrong key")
+	}
+}
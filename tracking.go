@@ -0,0 +1,175 @@
+package nylas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Webhook types of the tracking notifications enabled with Tracking, for more
+// info see: https://docs.nylas.com/reference#understanding-tracking-notifications
+const (
+	WebhookTypeMessageOpened = "message.opened"
+	WebhookTypeLinkClicked   = "message.link_clicked"
+	WebhookTypeThreadReplied = "thread.replied"
+)
+
+// TrackingEvent is implemented by the typed tracking notifications, allowing
+// them to be correlated with the message and Tracking.Payload they were sent
+// with.
+type TrackingEvent interface {
+	// TrackedMessageID returns the ID of the tracked message which was sent.
+	TrackedMessageID() string
+	// TrackingPayload returns the Tracking.Payload the message was sent
+	// with.
+	TrackingPayload() string
+}
+
+// TrackingRecent is a recent open or click of a tracked message.
+type TrackingRecent struct {
+	ID        int    `json:"id"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Timestamp int64  `json:"timestamp"`
+	// LinkIndex is the index in LinkClicked.LinkData of the clicked link,
+	// only set for clicks.
+	LinkIndex int `json:"link_index"`
+}
+
+// TrackedLink is a link in a tracked message and the number of times it was
+// clicked.
+type TrackedLink struct {
+	URL   string `json:"url"`
+	Count int    `json:"count"`
+}
+
+// MessageOpened is the notification sent when a message with open tracking
+// is opened.
+type MessageOpened struct {
+	MessageID   string           `json:"message_id"`
+	Payload     string           `json:"payload"`
+	SenderAppID int64            `json:"sender_app_id"`
+	Timestamp   int64            `json:"timestamp"`
+	Count       int              `json:"count"`
+	Recents     []TrackingRecent `json:"recents"`
+}
+
+// TrackedMessageID implements the TrackingEvent interface.
+func (e MessageOpened) TrackedMessageID() string { return e.MessageID }
+
+// TrackingPayload implements the TrackingEvent interface.
+func (e MessageOpened) TrackingPayload() string { return e.Payload }
+
+// LinkClicked is the notification sent when a link in a message with link
+// tracking is clicked.
+type LinkClicked struct {
+	MessageID   string           `json:"message_id"`
+	Payload     string           `json:"payload"`
+	SenderAppID int64            `json:"sender_app_id"`
+	Timestamp   int64            `json:"timestamp"`
+	LinkData    []TrackedLink    `json:"link_data"`
+	Recents     []TrackingRecent `json:"recents"`
+}
+
+// TrackedMessageID implements the TrackingEvent interface.
+func (e LinkClicked) TrackedMessageID() string { return e.MessageID }
+
+// TrackingPayload implements the TrackingEvent interface.
+func (e LinkClicked) TrackingPayload() string { return e.Payload }
+
+// Link returns the link clicked in a recent click, ok is false if the link
+// index is out of range.
+func (e LinkClicked) Link(click TrackingRecent) (link TrackedLink, ok bool) {
+	if click.LinkIndex < 0 || click.LinkIndex >= len(e.LinkData) {
+		return TrackedLink{}, false
+	}
+	return e.LinkData[click.LinkIndex], true
+}
+
+// ThreadReplied is the notification sent when a reply is received to a
+// message with thread reply tracking.
+type ThreadReplied struct {
+	// MessageID is the ID of the reply.
+	MessageID string `json:"message_id"`
+	// ReplyToMessageID is the ID of the tracked message replied to.
+	ReplyToMessageID string `json:"reply_to_message_id"`
+	ThreadID         string `json:"thread_id"`
+	Payload          string `json:"payload"`
+	SenderAppID      int64  `json:"sender_app_id"`
+	Timestamp        int64  `json:"timestamp"`
+	// FromSelf is true when the reply was sent by the account itself.
+	FromSelf bool `json:"from_self"`
+}
+
+// TrackedMessageID implements the TrackingEvent interface.
+func (e ThreadReplied) TrackedMessageID() string { return e.ReplyToMessageID }
+
+// TrackingPayload implements the TrackingEvent interface.
+func (e ThreadReplied) TrackingPayload() string { return e.Payload }
+
+// MessageOpened returns the metadata of a message.opened delta.
+func (d WebhookDelta) MessageOpened() (MessageOpened, error) {
+	var e MessageOpened
+	return e, d.decodeTracking(WebhookTypeMessageOpened, &e)
+}
+
+// LinkClicked returns the metadata of a message.link_clicked delta.
+func (d WebhookDelta) LinkClicked() (LinkClicked, error) {
+	var e LinkClicked
+	return e, d.decodeTracking(WebhookTypeLinkClicked, &e)
+}
+
+// ThreadReplied returns the metadata of a thread.replied delta.
+func (d WebhookDelta) ThreadReplied() (ThreadReplied, error) {
+	var e ThreadReplied
+	return e, d.decodeTracking(WebhookTypeThreadReplied, &e)
+}
+
+// TrackingEvent returns the typed metadata of a tracking delta, or nil if
+// the delta is not a tracking notification.
+func (d WebhookDelta) TrackingEvent() (TrackingEvent, error) {
+	switch d.Type {
+	case WebhookTypeMessageOpened:
+		return d.MessageOpened()
+	case WebhookTypeLinkClicked:
+		return d.LinkClicked()
+	case WebhookTypeThreadReplied:
+		return d.ThreadReplied()
+	}
+	return nil, nil
+}
+
+func (d WebhookDelta) decodeTracking(typ string, v interface{}) error {
+	if d.Type != typ {
+		return fmt.Errorf("webhook delta is %s not %s", d.Type, typ)
+	}
+	data, err := json.Marshal(d.ObjectData.Metadata)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshal %s metadata: %w", typ, err)
+	}
+	return nil
+}
+
+// EncodeTrackingPayload encodes v as base64 encoded JSON for use as a
+// Tracking.Payload, so tracking notifications can be correlated with it using
+// DecodeTrackingPayload.
+func EncodeTrackingPayload(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeTrackingPayload decodes the payload of a tracking notification
+// created with EncodeTrackingPayload into v.
+func DecodeTrackingPayload(payload string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return fmt.Errorf("decode tracking payload: %w", err)
+	}
+	return json.Unmarshal(data, v)
+}
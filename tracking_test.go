@@ -0,0 +1,135 @@
+package nylas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func unmarshalWebhookDelta(t *testing.T, data string) WebhookDelta {
+	t.Helper()
+	var d WebhookDelta
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		t.Fatalf("unmarshal delta: %v", err)
+	}
+	return d
+}
+
+func TestWebhookDeltaTracking(t *testing.T) {
+	tests := map[string]struct {
+		delta string
+		want  TrackingEvent
+	}{
+		"message opened": {
+			delta: `{"type": "message.opened", "object": "metadata", "object_data": {"metadata": {
+				"count": 2, "message_id": "m1", "payload": "p1", "sender_app_id": 64280,
+				"timestamp": 1502310540, "recents": [{"id": 0, "ip": "127.0.0.1",
+				"timestamp": 1502310540, "user_agent": "Mozilla/5.0"}]}}}`,
+			want: MessageOpened{
+				MessageID:   "m1",
+				Payload:     "p1",
+				SenderAppID: 64280,
+				Timestamp:   1502310540,
+				Count:       2,
+				Recents: []TrackingRecent{
+					{IP: "127.0.0.1", UserAgent: "Mozilla/5.0", Timestamp: 1502310540},
+				},
+			},
+		},
+		"link clicked": {
+			delta: `{"type": "message.link_clicked", "object": "metadata", "object_data": {"metadata": {
+				"link_data": [{"count": 1, "url": "https://example.com/a"}, {"count": 3, "url": "https://example.com/b"}],
+				"message_id": "m1", "payload": "p1", "sender_app_id": 64280, "timestamp": 1502313538,
+				"recents": [{"id": 1, "ip": "127.0.0.1", "link_index": 1, "timestamp": 1502313538,
+				"user_agent": "Mozilla/5.0"}]}}}`,
+			want: LinkClicked{
+				MessageID:   "m1",
+				Payload:     "p1",
+				SenderAppID: 64280,
+				Timestamp:   1502313538,
+				LinkData: []TrackedLink{
+					{URL: "https://example.com/a", Count: 1},
+					{URL: "https://example.com/b", Count: 3},
+				},
+				Recents: []TrackingRecent{
+					{ID: 1, IP: "127.0.0.1", UserAgent: "Mozilla/5.0", Timestamp: 1502313538, LinkIndex: 1},
+				},
+			},
+		},
+		"thread replied": {
+			delta: `{"type": "thread.replied", "object": "metadata", "object_data": {"metadata": {
+				"from_self": false, "message_id": "m2", "payload": "p1", "reply_to_message_id": "m1",
+				"sender_app_id": 64280, "thread_id": "t1", "timestamp": 1502310540}}}`,
+			want: ThreadReplied{
+				MessageID:        "m2",
+				ReplyToMessageID: "m1",
+				ThreadID:         "t1",
+				Payload:          "p1",
+				SenderAppID:      64280,
+				Timestamp:        1502310540,
+			},
+		},
+		"not tracking": {
+			delta: `{"type": "message.created", "object": "message", "object_data": {"id": "m1"}}`,
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, err := unmarshalWebhookDelta(t, tt.delta).TrackingEvent()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("TrackingEvent: (-got +want):\n%s", diff)
+			}
+			if got != nil && (got.TrackedMessageID() != "m1" || got.TrackingPayload() != "p1") {
+				t.Errorf("unexpected correlation: %s %s", got.TrackedMessageID(), got.TrackingPayload())
+			}
+		})
+	}
+}
+
+func TestWebhookDeltaTrackingErrors(t *testing.T) {
+	d := unmarshalWebhookDelta(t, `{"type": "message.opened", "object_data": {"metadata": {"count": "many"}}}`)
+	if _, err := d.MessageOpened(); err == nil {
+		t.Error("expected error for invalid metadata")
+	}
+	if _, err := d.LinkClicked(); err == nil {
+		t.Error("expected error for wrong delta type")
+	}
+}
+
+func TestLinkClickedLink(t *testing.T) {
+	e := LinkClicked{LinkData: []TrackedLink{{URL: "https://example.com"}}}
+	if link, ok := e.Link(TrackingRecent{LinkIndex: 0}); !ok || link.URL != "https://example.com" {
+		t.Errorf("unexpected link: %+v %v", link, ok)
+	}
+	if _, ok := e.Link(TrackingRecent{LinkIndex: 1}); ok {
+		t.Error("expected out of range link")
+	}
+}
+
+func TestTrackingPayload(t *testing.T) {
+	type payload struct {
+		Campaign  string `json:"c"`
+		Recipient string `json:"r"`
+	}
+	want := payload{Campaign: "spring", Recipient: "alice@example.com"}
+	encoded, err := EncodeTrackingPayload(want)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got payload
+	if err := DecodeTrackingPayload(encoded, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DecodeTrackingPayload: (-got +want):\n%s", diff)
+	}
+	if err := DecodeTrackingPayload("not base64!", &got); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+}
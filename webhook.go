@@ -1,6 +1,7 @@
 package nylas
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -22,6 +23,21 @@ import (
 //
 // See: https://docs.nylas.com/reference#receiving-notifications
 func WebhookHandler(clientSecret string, fn func(WebhookDelta) error) http.Handler {
+	return webhookHandler(clientSecret, func(_ context.Context, deltas []WebhookDelta) error {
+		for _, delta := range deltas {
+			if err := fn(delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// webhookHandler returns a http.Handler which verifies the webhook request and
+// calls fn with every delta included in it.
+func webhookHandler(
+	clientSecret string, fn func(context.Context, []WebhookDelta) error,
+) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			challenge := r.URL.Query().Get("challenge")
@@ -56,11 +72,9 @@ func WebhookHandler(clientSecret string, fn func(WebhookDelta) error) http.Handl
 			return
 		}
 
-		for _, delta := range resp.Deltas {
-			if err := fn(delta); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+		if err := fn(r.Context(), resp.Deltas); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 	})
 }
@@ -108,6 +122,7 @@ type WebhookDelta struct {
 
 		// used for tracking, see:
 		// https://docs.nylas.com/reference#understanding-tracking-notifications
+		// Use TrackingEvent or the typed methods to decode it.
 		Metadata map[string]interface{} `json:"metadata"`
 	} `json:"object_data"`
 }
@@ -0,0 +1,222 @@
+package nylas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// Webhook object constants, for more info see:
+// https://docs.nylas.com/reference#supported-webhook-triggers
+const (
+	WebhookObjectAccount = "account"
+	WebhookObjectContact = "contact"
+	WebhookObjectEvent   = "event"
+	WebhookObjectMessage = "message"
+	WebhookObjectThread  = "thread"
+)
+
+// defaultEnrichConcurrency is the number of objects fetched at once when no
+// concurrency is given to NewWebhookEnricher.
+const defaultEnrichConcurrency = 5
+
+// EnrichedWebhookDelta is a WebhookDelta along with the object it references.
+//
+// At most one of the object fields is set, matching ObjectData.Object. Deltas
+// for objects which cannot be enriched have none of them set.
+type EnrichedWebhookDelta struct {
+	WebhookDelta
+
+	// Err is set when the object could not be fetched because the access
+	// token of the account is missing or no longer valid, e.g. it wraps
+	// ErrTokenNotFound or an API *Error with a 401 status code.
+	Err error
+
+	// Deleted is true when the referenced object no longer exists, either
+	// because the delta is for a deletion or because it was removed before
+	// it could be fetched.
+	Deleted bool
+
+	Message *Message
+	Thread  *Thread
+	Event   *Event
+	Contact *Contact
+	// Account is left nil, with Err set, for deltas of accounts whose access
+	// token is no longer valid, e.g. account.invalid.
+	Account *Account
+}
+
+// WebhookEnricher fetches the objects referenced by webhook deltas using the
+// access tokens found in a TokenStore.
+type WebhookEnricher struct {
+	client *Client
+	tokens TokenStore
+	sem    *semaphore.Weighted
+}
+
+// NewWebhookEnricher returns a new WebhookEnricher which makes at most
+// concurrency requests at once, if concurrency is less than 1 a default of 5
+// is used.
+func NewWebhookEnricher(client *Client, tokens TokenStore, concurrency int) *WebhookEnricher {
+	if concurrency < 1 {
+		concurrency = defaultEnrichConcurrency
+	}
+	return &WebhookEnricher{
+		client: client,
+		tokens: tokens,
+		sem:    semaphore.NewWeighted(int64(concurrency)),
+	}
+}
+
+// enrichKey identifies a single object to be fetched.
+type enrichKey struct {
+	accountID, object, id string
+}
+
+// Enrich fetches the object referenced by each of the deltas.
+//
+// Objects referenced by more than one delta are only fetched once, and deltas
+// for unsupported objects or deletions are returned without fetching
+// anything. Deltas of accounts whose access token is missing or revoked are
+// returned with Err set, while the rest are still enriched. A non-nil error
+// is returned if the TokenStore fails or any other object cannot be fetched.
+func (e *WebhookEnricher) Enrich(
+	ctx context.Context, deltas []WebhookDelta,
+) ([]EnrichedWebhookDelta, error) {
+	enriched := make([]EnrichedWebhookDelta, len(deltas))
+	fetches := make(map[enrichKey][]int)
+	tokens := make(map[string]string)
+	for i, delta := range deltas {
+		enriched[i].WebhookDelta = delta
+
+		switch delta.ObjectData.Object {
+		case WebhookObjectAccount, WebhookObjectContact, WebhookObjectEvent,
+			WebhookObjectMessage, WebhookObjectThread:
+		default:
+			continue
+		}
+		if strings.HasSuffix(delta.Type, ".deleted") {
+			enriched[i].Deleted = true
+			continue
+		}
+
+		key := enrichKey{
+			accountID: delta.ObjectData.AccountID,
+			object:    delta.ObjectData.Object,
+			id:        delta.ObjectData.ID,
+		}
+		fetches[key] = append(fetches[key], i)
+		tokens[key.accountID] = ""
+	}
+
+	tokenErrs := make(map[string]error)
+	for accountID := range tokens {
+		token, err := e.tokens.AccessToken(ctx, accountID)
+		if errors.Is(err, ErrTokenNotFound) {
+			tokenErrs[accountID] = fmt.Errorf("account %s: %w", accountID, err)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("account %s: %w", accountID, err)
+		}
+		tokens[accountID] = token
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for key, indexes := range fetches {
+		key, indexes := key, indexes
+		if err := tokenErrs[key.accountID]; err != nil {
+			for _, i := range indexes {
+				enriched[i].Err = err
+			}
+			continue
+		}
+		if err := e.sem.Acquire(gctx, 1); err != nil {
+			g.Go(func() error { return err })
+			break
+		}
+		g.Go(func() error {
+			defer e.sem.Release(1)
+			client := e.client.As(tokens[key.accountID])
+			return e.fetch(gctx, client, key, enriched, indexes)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return enriched, nil
+}
+
+func (e *WebhookEnricher) fetch(
+	ctx context.Context, client *Client, key enrichKey,
+	enriched []EnrichedWebhookDelta, indexes []int,
+) error {
+	var set func(*EnrichedWebhookDelta)
+	var err error
+	switch key.object {
+	case WebhookObjectAccount:
+		var account Account
+		account, err = client.Account(ctx)
+		set = func(d *EnrichedWebhookDelta) { d.Account = &account }
+	case WebhookObjectContact:
+		var contact Contact
+		contact, err = client.Contact(ctx, key.id)
+		set = func(d *EnrichedWebhookDelta) { d.Contact = &contact }
+	case WebhookObjectEvent:
+		var event Event
+		event, err = client.Event(ctx, key.id, false)
+		set = func(d *EnrichedWebhookDelta) { d.Event = &event }
+	case WebhookObjectMessage:
+		var message Message
+		message, err = client.Message(ctx, key.id, false)
+		set = func(d *EnrichedWebhookDelta) { d.Message = &message }
+	case WebhookObjectThread:
+		var thread Thread
+		thread, err = client.Thread(ctx, key.id, false)
+		set = func(d *EnrichedWebhookDelta) { d.Thread = &thread }
+	}
+
+	switch {
+	case hasStatusCode(err, http.StatusNotFound):
+		set = func(d *EnrichedWebhookDelta) { d.Deleted = true }
+	case hasStatusCode(err, http.StatusUnauthorized, http.StatusForbidden):
+		err = fmt.Errorf("account %s: %w", key.accountID, err)
+		set = func(d *EnrichedWebhookDelta) { d.Err = err }
+	case err != nil:
+		return fmt.Errorf("get %s %s: %w", key.object, key.id, err)
+	}
+
+	for _, i := range indexes {
+		set(&enriched[i])
+	}
+	return nil
+}
+
+// EnrichedWebhookHandler returns a new http.Handler for handling Nylas
+// webhooks which calls fn with each delta after the object it references has
+// been fetched by the enricher.
+//
+// As with WebhookHandler the X-Nylas-Signature will be verified and any
+// non-nil error from enriching or from fn will result in a 500 response with
+// the error message. Deltas of accounts whose access token is missing or
+// revoked are passed to fn with Err set.
+func EnrichedWebhookHandler(
+	clientSecret string, e *WebhookEnricher, fn func(EnrichedWebhookDelta) error,
+) http.Handler {
+	return webhookHandler(clientSecret, func(ctx context.Context, deltas []WebhookDelta) error {
+		enriched, err := e.Enrich(ctx, deltas)
+		if err != nil {
+			return err
+		}
+		for _, delta := range enriched {
+			if err := fn(delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
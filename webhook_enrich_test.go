@@ -0,0 +1,158 @@
+package nylas
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testWebhookDelta(typ, object, accountID, id string) WebhookDelta {
+	var d WebhookDelta
+	d.Type = typ
+	d.Object = object
+	d.ObjectData.Object = object
+	d.ObjectData.AccountID = accountID
+	d.ObjectData.ID = id
+	return d
+}
+
+func TestWebhookEnricherEnrich(t *testing.T) {
+	var messageRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/messages/m1":
+			assertBasicAuth(t, r, "tokenA", "")
+			atomic.AddInt32(&messageRequests, 1)
+			_, _ = w.Write([]byte(`{"id": "m1", "subject": "hello"}`))
+		case "/threads/t1":
+			assertBasicAuth(t, r, "tokenB", "")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Couldn't find thread", "type": "invalid_request_error"}`))
+		case "/account":
+			assertBasicAuth(t, r, "tokenB", "")
+			_, _ = w.Write([]byte(`{"id": "b", "sync_state": "running"}`))
+		default:
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	deltas := []WebhookDelta{
+		testWebhookDelta("message.created", "message", "a", "m1"),
+		testWebhookDelta("message.updated", "message", "a", "m1"),
+		testWebhookDelta("thread.replied", "thread", "b", "t1"),
+		testWebhookDelta("event.deleted", "event", "b", "e1"),
+		testWebhookDelta("account.running", "account", "b", "b"),
+		testWebhookDelta("folder.created", "folder", "b", "f1"),
+	}
+
+	client := NewClient("", "", withTestServer(ts))
+	tokens := testTokenStore(t, map[string]string{"a": "tokenA", "b": "tokenB"})
+	got, err := NewWebhookEnricher(client, tokens, 2).Enrich(context.Background(), deltas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&messageRequests); n != 1 {
+		t.Errorf("message requests: got %d; want 1", n)
+	}
+
+	message := &Message{ID: "m1", Subject: "hello"}
+	want := []EnrichedWebhookDelta{
+		{WebhookDelta: deltas[0], Message: message},
+		{WebhookDelta: deltas[1], Message: message},
+		{WebhookDelta: deltas[2], Deleted: true},
+		{WebhookDelta: deltas[3], Deleted: true},
+		{WebhookDelta: deltas[4], Account: &Account{ID: "b", SyncState: "running"}},
+		{WebhookDelta: deltas[5]},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Enrich: (-got +want):\n%s", diff)
+	}
+}
+
+func TestWebhookEnricherEnrichTokenErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/messages/m1":
+			_, _ = w.Write([]byte(`{"id": "m1"}`))
+		case "/messages/m2", "/account":
+			assertBasicAuth(t, r, "revoked", "")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message": "Unauthorized", "type": "api_error"}`))
+		default:
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	deltas := []WebhookDelta{
+		testWebhookDelta("message.created", "message", "a", "m1"),
+		testWebhookDelta("message.created", "message", "revoked", "m2"),
+		testWebhookDelta("message.created", "message", "unknown", "m3"),
+		testWebhookDelta("account.invalid", "account", "revoked", "revoked"),
+	}
+	client := NewClient("", "", withTestServer(ts))
+	tokens := testTokenStore(t, map[string]string{"a": "tokenA", "revoked": "revoked"})
+	got, err := NewWebhookEnricher(client, tokens, 0).Enrich(context.Background(), deltas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got[0].Err != nil || got[0].Message == nil {
+		t.Errorf("got %+v; want message enriched", got[0])
+	}
+	if !hasStatusCode(got[1].Err, http.StatusUnauthorized) || got[1].Message != nil {
+		t.Errorf("got %+v; want 401 error", got[1])
+	}
+	if !errors.Is(got[2].Err, ErrTokenNotFound) || got[2].Message != nil {
+		t.Errorf("got %+v; want error %v", got[2], ErrTokenNotFound)
+	}
+	if !hasStatusCode(got[3].Err, http.StatusUnauthorized) || got[3].Account != nil {
+		t.Errorf("got %+v; want 401 error", got[3])
+	}
+}
+
+func TestEnrichedWebhookHandler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertMethodPath(t, r, http.MethodGet, "/messages/m1")
+		_, _ = w.Write([]byte(`{"id": "m1"}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient("", "", withTestServer(ts))
+	enricher := NewWebhookEnricher(client, testTokenStore(t, map[string]string{"a": "tokenA"}), 0)
+
+	var got []string
+	handler := EnrichedWebhookHandler("secret", enricher, func(d EnrichedWebhookDelta) error {
+		got = append(got, d.Message.ID)
+		return nil
+	})
+
+	body := `{"deltas": [{"type": "message.created", "object_data": ` +
+		`{"id": "m1", "object": "message", "account_id": "a"}}]}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(body))
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("X-Nylas-Signature", hex.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d; want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if diff := cmp.Diff(got, []string{"m1"}); diff != "" {
+		t.Errorf("handled: (-got +want):\n%s", diff)
+	}
+}